@@ -2,11 +2,38 @@ package audio
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
 	// ErrInvalidBuffer is a generic error returned when trying to read/write to an invalid buffer.
 	ErrInvalidBuffer = errors.New("invalid buffer")
+	// ErrFormatMismatch is returned when reading/writing frames between buffers
+	// or streams with incompatible formats.
+	ErrFormatMismatch = errors.New("format mismatch")
+	// ErrLayoutMismatch is returned when the channel layout of a format
+	// doesn't match its number of channels.
+	ErrLayoutMismatch = errors.New("channel layout mismatch")
+	// ErrUnsupportedEncoding is returned when trying to decode/encode samples
+	// using an encoding the package doesn't support.
+	ErrUnsupportedEncoding = errors.New("unsupported sample encoding")
+	// ErrNilFormat is returned when a buffer doesn't have a format.
+	ErrNilFormat = errors.New("nil format")
+	// ErrInvalidNumChannels is returned when a format doesn't have a
+	// positive number of channels.
+	ErrInvalidNumChannels = errors.New("invalid number of channels")
+	// ErrInvalidSampleRate is returned when a format doesn't have a positive
+	// sample rate.
+	ErrInvalidSampleRate = errors.New("invalid sample rate")
+	// ErrInvalidBitDepth is returned when a bit depth isn't supported by the
+	// samples it describes.
+	ErrInvalidBitDepth = errors.New("invalid bit depth")
+	// ErrChannelMismatch is returned when the data of a buffer doesn't match
+	// the number of channels of its format.
+	ErrChannelMismatch = errors.New("channel mismatch")
+	// ErrDataTypeMismatch is returned when the data of a PCMBuffer isn't in
+	// the store matching its DataType.
+	ErrDataTypeMismatch = errors.New("data type mismatch")
 )
 
 // Format is a high level representation of the underlying data.
@@ -15,6 +42,81 @@ type Format struct {
 	NumChannels int
 	// SampleRate is the sampling rate in Hz
 	SampleRate int
+	// Layout describes what each channel represents, it is optional.
+	Layout ChannelLayout
+	// Sample describes the encoding of the source samples (bit depth,
+	// signedness, endianness...), it is optional. Buffers without a
+	// SourceBitDepth use its bit depth.
+	Sample SampleEncoding
+}
+
+// Equal returns true if both formats are identical.
+func (f *Format) Equal(other *Format) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return *f == *other
+}
+
+// Compatible returns an error wrapping ErrFormatMismatch if frames of the
+// other format can't be mixed with or appended to frames of f: different
+// numbers of channels, sample rates or layouts (sample rates and layouts
+// being only compared when set in both formats, layouts by their channels
+// regardless of their names). Sample encodings may
+// differ since samples are converted. Nil formats are compatible with any
+// format.
+func (f *Format) Compatible(other *Format) error {
+	if f == nil || other == nil {
+		return nil
+	}
+	if numChannels(f) != numChannels(other) {
+		return fmt.Errorf("%w: %d channels vs %d channels", ErrFormatMismatch, numChannels(other), numChannels(f))
+	}
+	if f.SampleRate != 0 && other.SampleRate != 0 && f.SampleRate != other.SampleRate {
+		return fmt.Errorf("%w: %d Hz vs %d Hz", ErrFormatMismatch, other.SampleRate, f.SampleRate)
+	}
+	if !f.Layout.IsZero() && !other.Layout.IsZero() && !f.Layout.sameChannels(other.Layout) {
+		return fmt.Errorf("%w: %s layout vs %s layout", ErrFormatMismatch, other.Layout, f.Layout)
+	}
+	return nil
+}
+
+// String returns a description of the format such as
+// "44100 Hz, 2 channels (stereo), s16le".
+func (f *Format) String() string {
+	if f == nil {
+		return "<nil>"
+	}
+	s := fmt.Sprintf("%d Hz, %d channels", f.SampleRate, f.NumChannels)
+	if !f.Layout.IsZero() {
+		s += " (" + f.Layout.String() + ")"
+	}
+	if f.Sample.BitDepth != 0 {
+		s += ", " + f.Sample.String()
+	}
+	return s
+}
+
+// bitDepth returns the bit depth of the integer source samples described by
+// the format, or 0 if unknown.
+func (f *Format) bitDepth() int {
+	if f == nil || f.Sample.BitDepth == 0 {
+		return 0
+	}
+	switch f.Sample.Encoding {
+	case EncodingSigned, EncodingUnsigned:
+		return f.Sample.validBits()
+	}
+	return 0
+}
+
+// clone returns a copy of the format, or nil if f is nil.
+func (f *Format) clone() *Format {
+	if f == nil {
+		return nil
+	}
+	newF := *f
+	return &newF
 }
 
 // Buffer is the representation of an audio buffer.
@@ -22,6 +124,8 @@ type Buffer interface {
 	// PCMFormat is the format of buffer (describing the buffer content/format).
 	PCMFormat() *Format
 	// NumFrames returns the number of frames contained in the buffer.
+	// Buffers without a format have no frames and formats with 0 channels
+	// are treated as mono, see ValidateBuffer to reject such buffers.
 	NumFrames() int
 	// AsFloatBuffer returns a float 64 buffer from this buffer.
 	AsFloatBuffer() *FloatBuffer
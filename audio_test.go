@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSampleEncoding_String(t *testing.T) {
+	tests := []struct {
+		enc  SampleEncoding
+		want string
+	}{
+		{PCMS8, "s8"},
+		{PCMU8, "u8"},
+		{PCMS16LE, "s16le"},
+		{PCMS24BE, "s24be"},
+		{PCMS24In32LE, "s24/32le lsb-aligned"},
+		{SampleEncoding{BitDepth: 32, ValidBits: 24}, "s24/32le"},
+		{PCMF64BE, "f64be"},
+		{SampleEncoding{Encoding: EncodingMuLaw, BitDepth: 8}, "mulaw"},
+		{SampleEncoding{Encoding: EncodingALaw, BitDepth: 8}, "alaw"},
+		{SampleEncoding{}, "unspecified"},
+	}
+	for _, tt := range tests {
+		if got := tt.enc.String(); got != tt.want {
+			t.Errorf("%+v: expected %q, got %q", tt.enc, tt.want, got)
+		}
+	}
+}
+
+func TestFormat_String(t *testing.T) {
+	tests := []struct {
+		format *Format
+		want   string
+	}{
+		{nil, "<nil>"},
+		{FormatMono22500, "22500 Hz, 1 channels (mono)"},
+		{&Format{NumChannels: 2, SampleRate: 48000, Layout: LayoutStereo, Sample: PCMS24LE}, "48000 Hz, 2 channels (stereo), s24le"},
+	}
+	for _, tt := range tests {
+		if got := tt.format.String(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestFormat_Equal(t *testing.T) {
+	f := &Format{NumChannels: 2, SampleRate: 44100, Sample: PCMS16LE}
+	tests := []struct {
+		name  string
+		other *Format
+		want  bool
+	}{
+		{"same", &Format{NumChannels: 2, SampleRate: 44100, Sample: PCMS16LE}, true},
+		{"endianness", &Format{NumChannels: 2, SampleRate: 44100, Sample: PCMS16BE}, false},
+		{"sample rate", &Format{NumChannels: 2, SampleRate: 48000, Sample: PCMS16LE}, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := f.Equal(tt.other); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+	if !(*Format)(nil).Equal(nil) {
+		t.Error("expected nil formats to be equal")
+	}
+}
+
+func TestFormat_Compatible(t *testing.T) {
+	f := &Format{NumChannels: 2, SampleRate: 44100, Layout: LayoutStereo, Sample: PCMS16LE}
+	tests := []struct {
+		name  string
+		other *Format
+		want  error
+	}{
+		{"different encoding", &Format{NumChannels: 2, SampleRate: 44100, Sample: PCMF32LE}, nil},
+		{"unset sample rate", &Format{NumChannels: 2}, nil},
+		{"unnamed layout", &Format{NumChannels: 2, SampleRate: 44100, Layout: ChannelLayout{Mask: Mask(FrontLeft, FrontRight)}}, nil},
+		{"nil", nil, nil},
+		{"channels", FormatMono44100, ErrFormatMismatch},
+		{"sample rate", FormatStereo48000, ErrFormatMismatch},
+		{"layout", &Format{NumChannels: 2, SampleRate: 44100, Layout: ChannelLayout{Mask: Mask(FrontLeft, LowFrequency)}}, ErrFormatMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := f.Compatible(tt.other); !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFormat_BitDepth(t *testing.T) {
+	format := &Format{NumChannels: 1, SampleRate: 44100, Sample: PCMS24LE}
+	ib := &IntBuffer{Format: format, Data: []int{1 << 22, -1 << 23}}
+	fb := ib.AsFloatBuffer()
+	if fb.Data[0] != 0.5 || fb.Data[1] != -1 {
+		t.Errorf("expected samples scaled from 24 bits, got %v", fb.Data)
+	}
+	pb := &PCMBuffer{Format: format, I32: []int32{1 << 22}, DataType: DataTypeI32}
+	if got := pb.AsFloatBuffer().Data[0]; got != 0.5 {
+		t.Errorf("expected 0.5, got %v", got)
+	}
+	if got := (&FloatBuffer{Format: format, Data: []float64{0.5}}).AsIntBuffer().Data[0]; got != 1<<22 {
+		t.Errorf("expected %d, got %d", 1<<22, got)
+	}
+}
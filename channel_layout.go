@@ -0,0 +1,248 @@
+package audio
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// ChannelPosition is the position of the speaker a channel is meant for.
+// The values match the bits of the WAVEFORMATEXTENSIBLE channel mask.
+type ChannelPosition uint32
+
+// Speaker positions, in the order channels are interleaved.
+const (
+	FrontLeft ChannelPosition = 1 << iota
+	FrontRight
+	FrontCenter
+	LowFrequency
+	BackLeft
+	BackRight
+	FrontLeftOfCenter
+	FrontRightOfCenter
+	BackCenter
+	SideLeft
+	SideRight
+	TopCenter
+	TopFrontLeft
+	TopFrontCenter
+	TopFrontRight
+	TopBackLeft
+	TopBackCenter
+	TopBackRight
+)
+
+var positionNames = []string{
+	"FL", "FR", "FC", "LFE", "BL", "BR", "FLC", "FRC", "BC",
+	"SL", "SR", "TC", "TFL", "TFC", "TFR", "TBL", "TBC", "TBR",
+}
+
+// String returns the usual abbreviation of the position (FL, FR, LFE...).
+func (p ChannelPosition) String() string {
+	if bits.OnesCount32(uint32(p)) == 1 {
+		if i := bits.TrailingZeros32(uint32(p)); i < len(positionNames) {
+			return positionNames[i]
+		}
+	}
+	return fmt.Sprintf("ChannelPosition(%#x)", uint32(p))
+}
+
+// ChannelMask is a set of speaker positions.
+type ChannelMask uint32
+
+// Mask returns the mask containing the given positions.
+func Mask(positions ...ChannelPosition) ChannelMask {
+	var m ChannelMask
+	for _, p := range positions {
+		m |= ChannelMask(p)
+	}
+	return m
+}
+
+// Has returns true if the mask contains the position p.
+func (m ChannelMask) Has(p ChannelPosition) bool {
+	return p != 0 && m&ChannelMask(p) == ChannelMask(p)
+}
+
+// Count returns the number of positions in the mask.
+func (m ChannelMask) Count() int {
+	return bits.OnesCount32(uint32(m))
+}
+
+// Positions returns the positions of the mask in interleaving order.
+func (m ChannelMask) Positions() []ChannelPosition {
+	positions := make([]ChannelPosition, 0, m.Count())
+	for rest := uint32(m); rest != 0; rest &= rest - 1 {
+		positions = append(positions, ChannelPosition(rest&-rest))
+	}
+	return positions
+}
+
+// ChannelLayout describes what each channel of the audio data represents.
+// The zero value is an unspecified layout.
+//
+// Speaker channels are interleaved following the order of their positions
+// (the WAVE order: FL, FR, FC, LFE, BL, BR...), then come the discrete
+// channels which aren't meant for a specific speaker. Ambisonic layouts
+// use the ACN channel ordering.
+type ChannelLayout struct {
+	// Name is an optional name for the layout.
+	Name string
+	// Mask holds the speaker positions of the channels.
+	Mask ChannelMask
+	// Discrete is the number of channels that aren't associated with a
+	// speaker position (independent microphones for instance).
+	Discrete int
+	// Ambisonic indicates that the channels are ambisonic components.
+	Ambisonic bool
+	// AmbisonicOrder is the order of the ambisonic layout.
+	AmbisonicOrder int
+}
+
+// Standard channel layouts.
+var (
+	LayoutMono     = ChannelLayout{Name: "mono", Mask: Mask(FrontCenter)}
+	LayoutStereo   = ChannelLayout{Name: "stereo", Mask: Mask(FrontLeft, FrontRight)}
+	Layout2_1      = ChannelLayout{Name: "2.1", Mask: Mask(FrontLeft, FrontRight, LowFrequency)}
+	LayoutSurround = ChannelLayout{Name: "3.0", Mask: Mask(FrontLeft, FrontRight, FrontCenter)}
+	LayoutQuad     = ChannelLayout{Name: "quad", Mask: Mask(FrontLeft, FrontRight, BackLeft, BackRight)}
+	Layout5_0      = ChannelLayout{Name: "5.0", Mask: Mask(FrontLeft, FrontRight, FrontCenter, BackLeft, BackRight)}
+	Layout5_1      = ChannelLayout{Name: "5.1", Mask: Mask(FrontLeft, FrontRight, FrontCenter, LowFrequency, BackLeft, BackRight)}
+	Layout5_1Side  = ChannelLayout{Name: "5.1(side)", Mask: Mask(FrontLeft, FrontRight, FrontCenter, LowFrequency, SideLeft, SideRight)}
+	Layout7_1      = ChannelLayout{Name: "7.1", Mask: Mask(FrontLeft, FrontRight, FrontCenter, LowFrequency, BackLeft, BackRight, SideLeft, SideRight)}
+)
+
+// AmbisonicLayout returns the layout of a full sphere ambisonic signal of
+// the given order, made of (order+1)^2 channels.
+func AmbisonicLayout(order int) ChannelLayout {
+	return ChannelLayout{
+		Name:           fmt.Sprintf("ambisonic order %d", order),
+		Ambisonic:      true,
+		AmbisonicOrder: order,
+	}
+}
+
+// DiscreteLayout returns a layout of n channels without speaker positions.
+func DiscreteLayout(n int) ChannelLayout {
+	return ChannelLayout{Discrete: n}
+}
+
+// DefaultLayout returns the most common layout for the given number of
+// channels, or a discrete layout if there is none.
+func DefaultLayout(numChannels int) ChannelLayout {
+	switch numChannels {
+	case 1:
+		return LayoutMono
+	case 2:
+		return LayoutStereo
+	case 3:
+		return Layout2_1
+	case 4:
+		return LayoutQuad
+	case 5:
+		return Layout5_0
+	case 6:
+		return Layout5_1
+	case 8:
+		return Layout7_1
+	}
+	return DiscreteLayout(numChannels)
+}
+
+// LayoutFromChannelMask returns the layout of numChannels channels described
+// by a WAVEFORMATEXTENSIBLE channel mask. Like in WAV files, positions beyond
+// the number of channels are ignored and channels beyond the positions of
+// the mask are discrete.
+func LayoutFromChannelMask(mask uint32, numChannels int) ChannelLayout {
+	var l ChannelLayout
+	for rest := mask; rest != 0 && l.Mask.Count() < numChannels; rest &= rest - 1 {
+		l.Mask |= ChannelMask(rest & -rest)
+	}
+	l.Discrete = numChannels - l.Mask.Count()
+	for _, std := range []ChannelLayout{LayoutMono, LayoutStereo, Layout2_1, LayoutSurround, LayoutQuad, Layout5_0, Layout5_1, Layout5_1Side, Layout7_1} {
+		if l.Discrete == 0 && l.Mask == std.Mask {
+			return std
+		}
+	}
+	return l
+}
+
+// IsZero returns true if the layout is unspecified.
+func (l ChannelLayout) IsZero() bool {
+	return l == ChannelLayout{}
+}
+
+// sameChannels reports whether l and other describe the same channels,
+// ignoring their names.
+func (l ChannelLayout) sameChannels(other ChannelLayout) bool {
+	return l.Mask == other.Mask && l.Discrete == other.Discrete &&
+		l.Ambisonic == other.Ambisonic && l.AmbisonicOrder == other.AmbisonicOrder
+}
+
+// NumChannels returns the number of channels of the layout.
+func (l ChannelLayout) NumChannels() int {
+	if l.Ambisonic {
+		return (l.AmbisonicOrder + 1) * (l.AmbisonicOrder + 1)
+	}
+	return l.Mask.Count() + l.Discrete
+}
+
+// Positions returns the speaker position of each channel, discrete and
+// ambisonic channels having a 0 position.
+func (l ChannelLayout) Positions() []ChannelPosition {
+	positions := make([]ChannelPosition, l.NumChannels())
+	if !l.Ambisonic {
+		copy(positions, l.Mask.Positions())
+	}
+	return positions
+}
+
+// Index returns the index of the channel at the position p or -1 if the
+// layout doesn't contain that position.
+func (l ChannelLayout) Index(p ChannelPosition) int {
+	if l.Ambisonic || !l.Mask.Has(p) || bits.OnesCount32(uint32(p)) != 1 {
+		return -1
+	}
+	return bits.OnesCount32(uint32(l.Mask) & (uint32(p) - 1))
+}
+
+// ChannelMask returns the WAVEFORMATEXTENSIBLE channel mask of the layout.
+func (l ChannelLayout) ChannelMask() uint32 {
+	if l.Ambisonic {
+		return 0
+	}
+	return uint32(l.Mask)
+}
+
+// String returns the name of the layout or a description of its channels.
+func (l ChannelLayout) String() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	if l.IsZero() {
+		return "unspecified"
+	}
+	if l.Ambisonic {
+		return fmt.Sprintf("ambisonic order %d", l.AmbisonicOrder)
+	}
+	var names []string
+	for _, p := range l.Mask.Positions() {
+		names = append(names, p.String())
+	}
+	if l.Discrete > 0 {
+		names = append(names, fmt.Sprintf("%d discrete", l.Discrete))
+	}
+	return strings.Join(names, "+")
+}
+
+// CheckLayout returns an error wrapping ErrLayoutMismatch if the format's
+// layout is set and doesn't have NumChannels channels.
+func (f *Format) CheckLayout() error {
+	if f == nil || f.Layout.IsZero() {
+		return nil
+	}
+	if n := f.Layout.NumChannels(); n != f.NumChannels {
+		return fmt.Errorf("%w: %d channels in a %s layout of %d channels", ErrLayoutMismatch, f.NumChannels, f.Layout, n)
+	}
+	return nil
+}
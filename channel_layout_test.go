@@ -0,0 +1,109 @@
+package audio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestChannelLayout(t *testing.T) {
+	tests := []struct {
+		layout   ChannelLayout
+		channels int
+		mask     uint32
+		str      string
+	}{
+		{LayoutMono, 1, 0x4, "mono"},
+		{LayoutStereo, 2, 0x3, "stereo"},
+		{Layout2_1, 3, 0xB, "2.1"},
+		{LayoutQuad, 4, 0x33, "quad"},
+		{Layout5_1, 6, 0x3F, "5.1"},
+		{Layout5_1Side, 6, 0x60F, "5.1(side)"},
+		{Layout7_1, 8, 0x63F, "7.1"},
+		{AmbisonicLayout(1), 4, 0, "ambisonic order 1"},
+		{AmbisonicLayout(3), 16, 0, "ambisonic order 3"},
+		{DiscreteLayout(6), 6, 0, "6 discrete"},
+		{ChannelLayout{Mask: Mask(FrontLeft, LowFrequency), Discrete: 1}, 3, 0x9, "FL+LFE+1 discrete"},
+		{ChannelLayout{}, 0, 0, "unspecified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if n := tt.layout.NumChannels(); n != tt.channels {
+				t.Errorf("NumChannels() = %d, want %d", n, tt.channels)
+			}
+			if m := tt.layout.ChannelMask(); m != tt.mask {
+				t.Errorf("ChannelMask() = %#x, want %#x", m, tt.mask)
+			}
+			if s := tt.layout.String(); s != tt.str {
+				t.Errorf("String() = %q, want %q", s, tt.str)
+			}
+			if n := len(tt.layout.Positions()); n != tt.channels {
+				t.Errorf("expected %d positions, got %d", tt.channels, n)
+			}
+		})
+	}
+}
+
+func TestChannelLayout_Positions(t *testing.T) {
+	want := []ChannelPosition{FrontLeft, FrontRight, FrontCenter, LowFrequency, BackLeft, BackRight}
+	if got := Layout5_1.Positions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Positions() = %v, want %v", got, want)
+	}
+	if i := Layout5_1.Index(LowFrequency); i != 3 {
+		t.Errorf("expected the LFE channel to be at index 3, got %d", i)
+	}
+	if i := Layout5_1.Index(SideLeft); i != -1 {
+		t.Errorf("expected no SL channel, got %d", i)
+	}
+	if i := Layout7_1.Index(SideRight); i != 7 {
+		t.Errorf("expected the SR channel to be at index 7, got %d", i)
+	}
+	if s := TopBackRight.String(); s != "TBR" {
+		t.Errorf("unexpected position name %q", s)
+	}
+}
+
+func TestLayoutFromChannelMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		mask     uint32
+		channels int
+		want     ChannelLayout
+	}{
+		{"stereo", 0x3, 2, LayoutStereo},
+		{"5.1", 0x3F, 6, Layout5_1},
+		{"extra positions", 0x3F, 2, LayoutStereo},
+		{"extra channels", 0x3, 4, ChannelLayout{Mask: Mask(FrontLeft, FrontRight), Discrete: 2}},
+		{"no mask", 0, 3, DiscreteLayout(3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LayoutFromChannelMask(tt.mask, tt.channels); got != tt.want {
+				t.Errorf("LayoutFromChannelMask(%#x, %d) = %+v, want %+v", tt.mask, tt.channels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_CheckLayout(t *testing.T) {
+	if err := FormatStereo44100.CheckLayout(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := (&Format{NumChannels: 6}).CheckLayout(); err != nil {
+		t.Errorf("unexpected error for an unspecified layout %v", err)
+	}
+	f := &Format{NumChannels: 2, SampleRate: 48000, Layout: Layout5_1}
+	if err := f.CheckLayout(); !errors.Is(err, ErrLayoutMismatch) {
+		t.Errorf("expected ErrLayoutMismatch, got %v", err)
+	}
+}
+
+func TestFormat_LayoutIsCopied(t *testing.T) {
+	buf := &IntBuffer{Format: &Format{NumChannels: 6, SampleRate: 48000, Layout: Layout5_1}, Data: make([]int, 12)}
+	if l := buf.AsFloatBuffer().Format.Layout; l != Layout5_1 {
+		t.Errorf("expected the layout to be kept, got %v", l)
+	}
+	if l := buf.Clone().PCMFormat().Layout; l != Layout5_1 {
+		t.Errorf("expected the layout to be cloned, got %v", l)
+	}
+}
@@ -0,0 +1,249 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// PanLaw describes how the gains of the left and right channels evolve when
+// panning a mono signal.
+type PanLaw uint8
+
+const (
+	// ConstantPowerPan keeps the perceived loudness constant, a centered
+	// signal being attenuated by 3 dB on each side.
+	ConstantPowerPan PanLaw = iota
+	// LinearPan keeps the sum of the gains constant, a centered signal
+	// being attenuated by 6 dB on each side.
+	LinearPan
+	// BalancePan leaves the louder side at unity gain, a centered signal
+	// not being attenuated.
+	BalancePan
+)
+
+// ITU-R BS.775 downmix matrices, rows are output channels and columns
+// input channels, using the channel order of the standard layouts.
+var (
+	// MatrixStereoToMono downmixes LayoutStereo to LayoutMono.
+	MatrixStereoToMono = [][]float64{{math.Sqrt2 / 2, math.Sqrt2 / 2}}
+	// MatrixQuadToStereo downmixes LayoutQuad to LayoutStereo.
+	MatrixQuadToStereo = [][]float64{
+		{1, 0, math.Sqrt2 / 2, 0},
+		{0, 1, 0, math.Sqrt2 / 2},
+	}
+	// Matrix5_1ToStereo downmixes Layout5_1 to LayoutStereo, dropping the
+	// LFE channel.
+	Matrix5_1ToStereo = [][]float64{
+		{1, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2, 0},
+		{0, 1, math.Sqrt2 / 2, 0, 0, math.Sqrt2 / 2},
+	}
+	// Matrix7_1ToStereo downmixes Layout7_1 to LayoutStereo, dropping the
+	// LFE channel.
+	Matrix7_1ToStereo = [][]float64{
+		{1, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2, 0},
+		{0, 1, math.Sqrt2 / 2, 0, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2},
+	}
+	// Matrix7_1To5_1 downmixes Layout7_1 to Layout5_1, folding the side
+	// channels into the back channels.
+	Matrix7_1To5_1 = [][]float64{
+		{1, 0, 0, 0, 0, 0, 0, 0},
+		{0, 1, 0, 0, 0, 0, 0, 0},
+		{0, 0, 1, 0, 0, 0, 0, 0},
+		{0, 0, 0, 1, 0, 0, 0, 0},
+		{0, 0, 0, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2, 0},
+		{0, 0, 0, 0, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2},
+	}
+)
+
+// PanMatrix returns the matrix spreading a mono signal over two channels.
+// pan goes from -1 (left only) to 1 (right only), 0 being centered.
+func PanMatrix(pan float64, law PanLaw) [][]float64 {
+	pan = math.Max(-1, math.Min(1, pan))
+	var left, right float64
+	switch law {
+	case LinearPan:
+		left, right = (1-pan)/2, (1+pan)/2
+	case BalancePan:
+		left, right = math.Min(1, 1-pan), math.Min(1, 1+pan)
+	default:
+		angle := (pan + 1) * math.Pi / 4
+		left, right = math.Cos(angle), math.Sin(angle)
+	}
+	return [][]float64{{left}, {right}}
+}
+
+// MixMatrix returns the built-in matrix converting channels from one layout
+// to another. It supports the identity, the BS.775 downmixes and the mono to
+// stereo upmix (using a centered constant power pan). The returned matrix is
+// a copy the caller may modify.
+func MixMatrix(from, to ChannelLayout) ([][]float64, error) {
+	if from.sameChannels(to) {
+		n := from.NumChannels()
+		m := make([][]float64, n)
+		for i := range m {
+			m[i] = make([]float64, n)
+			m[i][i] = 1
+		}
+		return m, nil
+	}
+	switch {
+	case from.Mask == LayoutStereo.Mask && to.Mask == LayoutMono.Mask:
+		return copyMatrix(MatrixStereoToMono), nil
+	case from.Mask == LayoutMono.Mask && to.Mask == LayoutStereo.Mask:
+		return PanMatrix(0, ConstantPowerPan), nil
+	case from.Mask == LayoutQuad.Mask && to.Mask == LayoutStereo.Mask:
+		return copyMatrix(MatrixQuadToStereo), nil
+	case from.Mask == Layout5_1.Mask && to.Mask == LayoutStereo.Mask:
+		return copyMatrix(Matrix5_1ToStereo), nil
+	case from.Mask == Layout7_1.Mask && to.Mask == LayoutStereo.Mask:
+		return copyMatrix(Matrix7_1ToStereo), nil
+	case from.Mask == Layout7_1.Mask && to.Mask == Layout5_1.Mask:
+		return copyMatrix(Matrix7_1To5_1), nil
+	}
+	return nil, fmt.Errorf("no built-in matrix to convert %s to %s", from, to)
+}
+
+// copyMatrix returns a deep copy of m.
+func copyMatrix(m [][]float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i, row := range m {
+		out[i] = append([]float64(nil), row...)
+	}
+	return out
+}
+
+// ChannelMixer converts interleaved audio data from a number of channels to
+// another by applying a gain matrix.
+type ChannelMixer struct {
+	// Matrix holds, for each output channel (rows), the gain applied to
+	// each input channel (columns).
+	Matrix [][]float64
+	// Layout is the optional layout of the output channels.
+	Layout ChannelLayout
+}
+
+// NewChannelMixer returns a mixer converting channels from one layout to
+// another using the built-in matrices, see MixMatrix.
+func NewChannelMixer(from, to ChannelLayout) (*ChannelMixer, error) {
+	m, err := MixMatrix(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &ChannelMixer{Matrix: m, Layout: to}, nil
+}
+
+// Process applies the matrix to buf in place and updates the number of
+// channels (and layout) of its format. The buffer's storage is reused unless
+// the number of channels grows or the samples are packed (U8 and I24 stores).
+// Integer samples are rounded and saturated to their bit depth.
+// Supported buffers are FloatBuffer, Float32Buffer, IntBuffer and PCMBuffer.
+func (m *ChannelMixer) Process(buf Buffer) error {
+	if len(m.Matrix) == 0 {
+		return fmt.Errorf("%w: empty mixing matrix", ErrInvalidBuffer)
+	}
+	in := len(m.Matrix[0])
+	for _, row := range m.Matrix {
+		if len(row) != in {
+			return fmt.Errorf("%w: the mixing matrix isn't rectangular", ErrInvalidBuffer)
+		}
+	}
+	if nc := numChannels(buf.PCMFormat()); nc != in {
+		return fmt.Errorf("%w: %d channels buffer processed by a %d channels mixer", ErrFormatMismatch, nc, in)
+	}
+	out := len(m.Matrix)
+	frames := buf.NumFrames()
+	format := buf.PCMFormat().clone()
+	if format == nil {
+		format = &Format{}
+	}
+	format.NumChannels = out
+	format.Layout = ChannelLayout{}
+	if m.Layout.NumChannels() == out {
+		format.Layout = m.Layout
+	}
+
+	switch b := buf.(type) {
+	case *FloatBuffer:
+		b.Data = mixSamples(m, b.Data, frames, 0)
+		b.Format = format
+	case *Float32Buffer:
+		b.Data = mixSamples(m, b.Data, frames, 0)
+		b.Format = format
+	case *IntBuffer:
+		b.Data = mixSamples(m, b.Data, frames, b.bitDepth())
+		b.Format = format
+	case *PCMBuffer:
+		var bitDepth int
+		if b.isInt() {
+			bitDepth = b.intBitDepth()
+		}
+		switch b.DataType {
+		case DataTypeI8:
+			b.I8 = mixSamples(m, b.I8, frames, bitDepth)
+		case DataTypeI16:
+			b.I16 = mixSamples(m, b.I16, frames, bitDepth)
+		case DataTypeI32:
+			b.I32 = mixSamples(m, b.I32, frames, bitDepth)
+		case DataTypeF32:
+			b.F32 = mixSamples(m, b.F32, frames, bitDepth)
+		case DataTypeF64:
+			b.F64 = mixSamples(m, b.F64, frames, bitDepth)
+		case DataTypeU8, DataTypeI24, DataTypeI24BE:
+			bitDepth = b.DataType.containerBitDepth()
+			b.setPacked(mixSamples(m, convertPCM[int32](b, bitDepth), frames, bitDepth))
+		}
+		b.Format = format
+	default:
+		return fmt.Errorf("%w: unsupported buffer type %T", ErrInvalidBuffer, buf)
+	}
+	return nil
+}
+
+// mixSamples applies the matrix to the frames of interleaved data and
+// returns the output frames, reusing the storage of data unless the number
+// of channels grows. Integer samples use bitDepth bits.
+func mixSamples[T Sample](m *ChannelMixer, data []T, frames, bitDepth int) []T {
+	in, out := len(m.Matrix[0]), len(m.Matrix)
+	data = data[:frames*in]
+	if out > in {
+		grown := make([]T, frames*out)
+		copy(grown, data)
+		data = grown
+	}
+	set := func(i int, v float64) { data[i] = T(v) }
+	if _, isFloat := sampleType[T](); !isFloat {
+		scale := intScale(bitDepth)
+		set = func(i int, v float64) { data[i] = T(Float64ToInt(v/scale, bitDepth)) }
+	}
+	m.mix(frames, func(i int) float64 { return float64(data[i]) }, set)
+	return data[:frames*out]
+}
+
+// mix applies the matrix to the frames of interleaved data accessed through
+// get and set. The data must have room for the output frames. Frames are
+// processed in an order that allows the output to overwrite the input.
+func (m *ChannelMixer) mix(frames int, get func(i int) float64, set func(i int, v float64)) {
+	in, out := len(m.Matrix[0]), len(m.Matrix)
+	frame := make([]float64, in)
+	process := func(f int) {
+		for ch := range frame {
+			frame[ch] = get(f*in + ch)
+		}
+		for o, row := range m.Matrix {
+			var v float64
+			for ch, g := range row {
+				v += g * frame[ch]
+			}
+			set(f*out+o, v)
+		}
+	}
+	if out <= in {
+		for f := 0; f < frames; f++ {
+			process(f)
+		}
+		return
+	}
+	for f := frames - 1; f >= 0; f-- {
+		process(f)
+	}
+}
@@ -0,0 +1,148 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestChannelMixer_Downmix(t *testing.T) {
+	m, err := NewChannelMixer(Layout5_1, LayoutStereo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &FloatBuffer{
+		Format: &Format{NumChannels: 6, SampleRate: 48000, Layout: Layout5_1},
+		Data: []float64{
+			0.1, 0.2, 0.4, 1, 0.2, 0.1,
+			0, 0, 0, 0, 0, 0,
+		},
+	}
+	data := buf.Data
+	if err := m.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+	s := math.Sqrt2 / 2
+	want := []float64{0.1 + 0.4*s + 0.2*s, 0.2 + 0.4*s + 0.1*s, 0, 0}
+	if len(buf.Data) != len(want) {
+		t.Fatalf("expected %d samples, got %d", len(want), len(buf.Data))
+	}
+	for i := range want {
+		if math.Abs(buf.Data[i]-want[i]) > 1e-12 {
+			t.Errorf("expected %v got %v", want, buf.Data)
+			break
+		}
+	}
+	if &data[0] != &buf.Data[0] {
+		t.Error("expected the downmix to happen in place")
+	}
+	if buf.Format.NumChannels != 2 || buf.Format.Layout != LayoutStereo {
+		t.Errorf("unexpected output format %+v", buf.Format)
+	}
+}
+
+func TestChannelMixer_Upmix(t *testing.T) {
+	buf := &IntBuffer{Format: FormatMono44100, Data: []int{100, -200, 32767}, SourceBitDepth: 16}
+	m := &ChannelMixer{Matrix: PanMatrix(-1, ConstantPowerPan)}
+	if err := m.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{100, 0, -200, 0, 32767, 0}
+	if !reflect.DeepEqual(buf.Data, want) {
+		t.Errorf("expected %v got %v", want, buf.Data)
+	}
+	if FormatMono44100.NumChannels != 1 {
+		t.Fatal("the shared format was modified")
+	}
+	if buf.Format.NumChannels != 2 || !buf.Format.Layout.IsZero() {
+		t.Errorf("unexpected output format %+v", buf.Format)
+	}
+}
+
+func TestChannelMixer_Saturation(t *testing.T) {
+	buf := &PCMBuffer{Format: FormatStereo44100, I16: []int16{32000, 32000, -32000, -32000}, DataType: DataTypeI16, SourceBitDepth: 2}
+	m, err := NewChannelMixer(LayoutStereo, LayoutMono)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int16{32767, -32768}; !reflect.DeepEqual(buf.I16, want) {
+		t.Errorf("expected %v got %v", want, buf.I16)
+	}
+	if buf.Format.Layout != LayoutMono {
+		t.Errorf("unexpected layout %v", buf.Format.Layout)
+	}
+}
+
+func TestChannelMixer_AllTypes(t *testing.T) {
+	m := &ChannelMixer{Matrix: [][]float64{{0.5, 0.5}, {0.5, -0.5}, {1, 0}}}
+	want := []float64{0.375, 0.125, 0.5, 0, 0, 0}
+	bufs := []Buffer{
+		&FloatBuffer{Format: FormatStereo44100, Data: []float64{0.5, 0.25, 0, 0}},
+		&Float32Buffer{Format: FormatStereo44100, Data: []float32{0.5, 0.25, 0, 0}},
+		&PCMBuffer{Format: FormatStereo44100, F64: []float64{0.5, 0.25, 0, 0}, DataType: DataTypeF64},
+		&PCMBuffer{Format: FormatStereo44100, F32: []float32{0.5, 0.25, 0, 0}, DataType: DataTypeF32},
+		&PCMBuffer{Format: FormatStereo44100, I8: []int8{64, 32, 0, 0}, DataType: DataTypeI8, SourceBitDepth: 1},
+		&PCMBuffer{Format: FormatStereo44100, I32: []int32{1 << 30, 1 << 29, 0, 0}, DataType: DataTypeI32, SourceBitDepth: 4},
+		&PCMBuffer{Format: FormatStereo44100, U8: []uint8{192, 160, 128, 128}, DataType: DataTypeU8},
+		&PCMBuffer{Format: FormatStereo44100, I24: []byte{0, 0, 0x40, 0, 0, 0x20, 0, 0, 0, 0, 0, 0}, DataType: DataTypeI24},
+	}
+	for _, buf := range bufs {
+		if err := m.Process(buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.AsFloatBuffer().Data; !reflect.DeepEqual(got, want) {
+			t.Errorf("%T: expected %v got %v", buf, want, got)
+		}
+	}
+}
+
+func TestChannelMixer_Errors(t *testing.T) {
+	m := &ChannelMixer{Matrix: MatrixStereoToMono}
+	buf := &FloatBuffer{Format: &Format{NumChannels: 6}, Data: make([]float64, 6)}
+	if err := m.Process(buf); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	if _, err := NewChannelMixer(LayoutQuad, Layout5_1); err == nil {
+		t.Error("expected an error for an unsupported conversion")
+	}
+}
+
+func TestPanMatrix(t *testing.T) {
+	tests := []struct {
+		name        string
+		pan         float64
+		law         PanLaw
+		left, right float64
+	}{
+		{"constant power center", 0, ConstantPowerPan, math.Sqrt2 / 2, math.Sqrt2 / 2},
+		{"constant power right", 1, ConstantPowerPan, 0, 1},
+		{"linear center", 0, LinearPan, 0.5, 0.5},
+		{"linear left", -1, LinearPan, 1, 0},
+		{"balance center", 0, BalancePan, 1, 1},
+		{"balance half left", -0.5, BalancePan, 1, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := PanMatrix(tt.pan, tt.law)
+			if math.Abs(m[0][0]-tt.left) > 1e-12 || math.Abs(m[1][0]-tt.right) > 1e-12 {
+				t.Errorf("PanMatrix(%v) = %v, want %v, %v", tt.pan, m, tt.left, tt.right)
+			}
+		})
+	}
+}
+
+func TestMixMatrix_Copy(t *testing.T) {
+	m, err := NewChannelMixer(Layout5_1, LayoutStereo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Matrix5_1ToStereo[0][0]
+	m.Matrix[0][0] = 42
+	if Matrix5_1ToStereo[0][0] != want {
+		t.Errorf("expected the built-in matrix to be left untouched, got %v", Matrix5_1ToStereo[0][0])
+	}
+}
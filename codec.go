@@ -0,0 +1,426 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Encoding is the numeric representation of encoded samples.
+type Encoding uint8
+
+const (
+	// EncodingSigned indicates two's complement signed integer samples.
+	EncodingSigned Encoding = iota
+	// EncodingUnsigned indicates unsigned integer samples, the zero value
+	// being half of the range (128 for 8-bit samples).
+	EncodingUnsigned
+	// EncodingFloat indicates IEEE 754 floating point samples.
+	EncodingFloat
+	// EncodingMuLaw indicates 8-bit G.711 µ-law companded samples.
+	EncodingMuLaw
+	// EncodingALaw indicates 8-bit G.711 A-law companded samples.
+	EncodingALaw
+)
+
+var encodingPrefixes = map[Encoding]string{
+	EncodingSigned:   "s",
+	EncodingUnsigned: "u",
+	EncodingFloat:    "f",
+}
+
+// SampleEncoding describes how samples are laid out in a byte stream.
+type SampleEncoding struct {
+	// Encoding is the numeric representation of the samples.
+	Encoding Encoding
+	// BitDepth is the size in bits of the container of each sample:
+	// 8, 16, 24 or 32 for integers, 32 or 64 for floats.
+	BitDepth int
+	// ValidBits is the number of significant bits of each sample,
+	// 0 meaning all the bits of the container.
+	ValidBits int
+	// BigEndian indicates that the most significant byte comes first.
+	BigEndian bool
+	// LSBAligned indicates that the valid bits are the least significant
+	// bits of the container (like ALSA's S24_LE), otherwise they are the
+	// most significant bits (like in WAV and AIFF files).
+	LSBAligned bool
+}
+
+// Common sample encodings.
+var (
+	PCMS8        = SampleEncoding{Encoding: EncodingSigned, BitDepth: 8}
+	PCMU8        = SampleEncoding{Encoding: EncodingUnsigned, BitDepth: 8}
+	PCMS16LE     = SampleEncoding{Encoding: EncodingSigned, BitDepth: 16}
+	PCMS16BE     = SampleEncoding{Encoding: EncodingSigned, BitDepth: 16, BigEndian: true}
+	PCMS24LE     = SampleEncoding{Encoding: EncodingSigned, BitDepth: 24}
+	PCMS24BE     = SampleEncoding{Encoding: EncodingSigned, BitDepth: 24, BigEndian: true}
+	PCMS24In32LE = SampleEncoding{Encoding: EncodingSigned, BitDepth: 32, ValidBits: 24, LSBAligned: true}
+	PCMS32LE     = SampleEncoding{Encoding: EncodingSigned, BitDepth: 32}
+	PCMS32BE     = SampleEncoding{Encoding: EncodingSigned, BitDepth: 32, BigEndian: true}
+	PCMF32LE     = SampleEncoding{Encoding: EncodingFloat, BitDepth: 32}
+	PCMF32BE     = SampleEncoding{Encoding: EncodingFloat, BitDepth: 32, BigEndian: true}
+	PCMF64LE     = SampleEncoding{Encoding: EncodingFloat, BitDepth: 64}
+	PCMF64BE     = SampleEncoding{Encoding: EncodingFloat, BitDepth: 64, BigEndian: true}
+)
+
+// Size returns the number of bytes used by each encoded sample.
+func (e SampleEncoding) Size() int {
+	return e.BitDepth / 8
+}
+
+// Validate returns an error wrapping ErrUnsupportedEncoding if the
+// encoding doesn't describe valid samples.
+func (e SampleEncoding) Validate() error {
+	switch e.Encoding {
+	case EncodingMuLaw, EncodingALaw:
+		if e.BitDepth != 8 {
+			return fmt.Errorf("%w: %d-bit companded samples", ErrUnsupportedEncoding, e.BitDepth)
+		}
+	case EncodingSigned, EncodingUnsigned:
+		switch e.BitDepth {
+		case 8, 16, 24, 32:
+		default:
+			return fmt.Errorf("%w: %d-bit integers", ErrUnsupportedEncoding, e.BitDepth)
+		}
+		if e.ValidBits < 0 || e.ValidBits > e.BitDepth {
+			return fmt.Errorf("%w: %d valid bits in a %d-bit container", ErrUnsupportedEncoding, e.ValidBits, e.BitDepth)
+		}
+	case EncodingFloat:
+		if e.BitDepth != 32 && e.BitDepth != 64 {
+			return fmt.Errorf("%w: %d-bit floats", ErrUnsupportedEncoding, e.BitDepth)
+		}
+	default:
+		return fmt.Errorf("%w: unknown encoding %d", ErrUnsupportedEncoding, e.Encoding)
+	}
+	return nil
+}
+
+// String returns a short description of the encoding such as "s16le",
+// "f32be", "u8" or "s24/32le" (24 valid bits in 32-bit containers).
+func (e SampleEncoding) String() string {
+	switch e.Encoding {
+	case EncodingMuLaw:
+		return "mulaw"
+	case EncodingALaw:
+		return "alaw"
+	}
+	prefix, ok := encodingPrefixes[e.Encoding]
+	if !ok {
+		return fmt.Sprintf("Encoding(%d)", e.Encoding)
+	}
+	if e.BitDepth == 0 {
+		return "unspecified"
+	}
+	s := fmt.Sprintf("%s%d", prefix, e.BitDepth)
+	if v := e.validBits(); v != e.BitDepth {
+		s = fmt.Sprintf("%s%d/%d", prefix, v, e.BitDepth)
+	}
+	if e.BitDepth > 8 {
+		if e.BigEndian {
+			s += "be"
+		} else {
+			s += "le"
+		}
+	}
+	if e.LSBAligned && e.validBits() != e.BitDepth {
+		s += " lsb-aligned"
+	}
+	return s
+}
+
+// checkCodec returns an error wrapping ErrUnsupportedEncoding if DecodePCM
+// and EncodePCM don't support the encoding.
+func (e SampleEncoding) checkCodec() error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if e.Encoding == EncodingMuLaw || e.Encoding == EncodingALaw {
+		return fmt.Errorf("%w: %s isn't supported by the codec", ErrUnsupportedEncoding, e)
+	}
+	return nil
+}
+
+// validBits returns the number of significant bits of each sample.
+func (e SampleEncoding) validBits() int {
+	if e.ValidBits == 0 || e.Encoding == EncodingFloat {
+		return e.BitDepth
+	}
+	return e.ValidBits
+}
+
+// byteOrder reads and appends multi-byte samples.
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// byteOrder returns the byte order of multi-byte samples.
+func (e SampleEncoding) byteOrder() byteOrder {
+	if e.BigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// dataType returns the PCMBuffer store samples of this encoding get
+// decoded to and the bit depth of the decoded samples.
+func (e SampleEncoding) dataType() (PCMDataFormat, int) {
+	switch {
+	case e.Encoding == EncodingFloat && e.BitDepth == 32:
+		return DataTypeF32, 32
+	case e.Encoding == EncodingFloat:
+		return DataTypeF64, 64
+	case e.BitDepth == 8 && e.Encoding == EncodingUnsigned:
+		return DataTypeU8, 8
+	case e.BitDepth == 8:
+		return DataTypeI8, 8
+	case e.BitDepth == 16:
+		return DataTypeI16, 16
+	case e.BitDepth == 24 && e.BigEndian:
+		return DataTypeI24BE, 24
+	case e.BitDepth == 24:
+		return DataTypeI24, 24
+	case e.validBits() == 24:
+		// keep the 24-bit samples as is in the int32 store
+		return DataTypeI32, 24
+	}
+	return DataTypeI32, 32
+}
+
+// DecodePCM decodes the samples encoded in src into dst, replacing its
+// content (the storage is reused when large enough). Only whole frames are
+// decoded, based on the number of channels of dst.Format, and the number of
+// consumed bytes is returned so the remaining bytes can be passed again with
+// the following data of a stream.
+//
+// If dst.DataType is DataTypeUnknown, it is set to the store best matching
+// the encoding: I8, U8, I16, I24/I24BE, I32 (24-bit samples in 32-bit
+// containers keeping their 24-bit values), F32 or F64. Otherwise the samples
+// are converted to the existing data type.
+func DecodePCM(dst *PCMBuffer, src []byte, enc SampleEncoding) (n int, err error) {
+	if dst == nil {
+		return 0, ErrInvalidBuffer
+	}
+	if err := enc.checkCodec(); err != nil {
+		return 0, err
+	}
+	nc := numChannels(dst.Format)
+	size := enc.Size()
+	samples := len(src) / (size * nc) * nc
+	n = samples * size
+	src = src[:n]
+
+	t, bitDepth := enc.dataType()
+	out := dst
+	if dst.DataType != DataTypeUnknown && dst.DataType != t {
+		// decode into scratch space and convert into the store of dst
+		out = &PCMBuffer{Format: dst.Format}
+	}
+	out.DataType = t
+	if enc.Encoding != EncodingFloat {
+		out.SourceBitDepth = uint8(bitDepth / 8)
+	}
+	decodeSamples(out, src, samples, enc, bitDepth)
+	if out != dst {
+		// keep track of the bit depth of the samples in the store of dst
+		// like SwitchPrimaryType does
+		depth := out.sourceBitDepth()
+		if dst.isInt() {
+			depth = out.storeBitDepth(dst.DataType)
+		}
+		dst.SourceBitDepth = uint8(depth / 8)
+		dst.resizeStore(samples)
+		copySamples(dst, 0, out)
+	}
+	return n, nil
+}
+
+// resizeStore resizes the primary store of b to n samples, reusing its
+// storage if it is large enough.
+func (b *PCMBuffer) resizeStore(n int) {
+	switch b.DataType {
+	case DataTypeI8:
+		b.I8 = resize(b.I8, n)
+	case DataTypeI16:
+		b.I16 = resize(b.I16, n)
+	case DataTypeI32:
+		b.I32 = resize(b.I32, n)
+	case DataTypeF32:
+		b.F32 = resize(b.F32, n)
+	case DataTypeF64:
+		b.F64 = resize(b.F64, n)
+	case DataTypeU8:
+		b.U8 = resize(b.U8, n)
+	case DataTypeI24, DataTypeI24BE:
+		b.I24 = resize(b.I24, 3*n)
+	}
+}
+
+// decodeSamples decodes the samples of src into the primary store of b,
+// integer samples being scaled to bitDepth bits.
+func decodeSamples(b *PCMBuffer, src []byte, samples int, enc SampleEncoding, bitDepth int) {
+	order := enc.byteOrder()
+	plain := enc.Encoding == EncodingSigned && enc.validBits() == enc.BitDepth && bitDepth == enc.BitDepth
+	switch {
+	case enc.Encoding == EncodingFloat && enc.BitDepth == 32:
+		b.F32 = resize(b.F32, samples)
+		for i := range b.F32 {
+			b.F32[i] = math.Float32frombits(order.Uint32(src[4*i:]))
+		}
+	case enc.Encoding == EncodingFloat:
+		b.F64 = resize(b.F64, samples)
+		for i := range b.F64 {
+			b.F64[i] = math.Float64frombits(order.Uint64(src[8*i:]))
+		}
+	case b.DataType == DataTypeU8 && enc.validBits() == 8:
+		b.U8 = append(b.U8[:0], src...)
+	case plain && enc.BitDepth == 8:
+		b.I8 = resize(b.I8, samples)
+		for i, s := range src {
+			b.I8[i] = int8(s)
+		}
+	case plain && enc.BitDepth == 16:
+		b.I16 = resize(b.I16, samples)
+		for i := range b.I16 {
+			b.I16[i] = int16(order.Uint16(src[2*i:]))
+		}
+	case plain && enc.BitDepth == 24:
+		b.I24 = append(b.I24[:0], src...)
+	case plain && enc.BitDepth == 32:
+		b.I32 = resize(b.I32, samples)
+		for i := range b.I32 {
+			b.I32[i] = int32(order.Uint32(src[4*i:]))
+		}
+	default:
+		decodeInts(b, src, samples, enc, bitDepth)
+	}
+}
+
+// decodeInts is the generic integer decoding path, handling any
+// signedness, endianness and alignment of the valid bits.
+func decodeInts(b *PCMBuffer, src []byte, samples int, enc SampleEncoding, bitDepth int) {
+	size := enc.Size()
+	valid := enc.validBits()
+	var set func(i int, v int64)
+	switch b.DataType {
+	case DataTypeU8:
+		b.U8 = resize(b.U8, samples)
+		set = func(i int, v int64) { b.U8[i] = uint8(v + 128) }
+	case DataTypeI8:
+		b.I8 = resize(b.I8, samples)
+		set = func(i int, v int64) { b.I8[i] = int8(v) }
+	case DataTypeI16:
+		b.I16 = resize(b.I16, samples)
+		set = func(i int, v int64) { b.I16[i] = int16(v) }
+	case DataTypeI24:
+		b.I24 = resize(b.I24, 3*samples)
+		set = func(i int, v int64) { PutInt24LE(b.I24[3*i:], int32(v)) }
+	case DataTypeI24BE:
+		b.I24 = resize(b.I24, 3*samples)
+		set = func(i int, v int64) { PutInt24BE(b.I24[3*i:], int32(v)) }
+	default:
+		b.I32 = resize(b.I32, samples)
+		set = func(i int, v int64) { b.I32[i] = int32(v) }
+	}
+	for i := 0; i < samples; i++ {
+		var raw uint64
+		for k := 0; k < size; k++ {
+			shift := uint(8 * k)
+			if enc.BigEndian {
+				shift = uint(8 * (size - 1 - k))
+			}
+			raw |= uint64(src[i*size+k]) << shift
+		}
+		bits := enc.BitDepth
+		if enc.LSBAligned {
+			// ignore the unused most significant bits
+			bits = valid
+			raw &= 1<<uint(bits) - 1
+		}
+		var v int64
+		if enc.Encoding == EncodingUnsigned {
+			v = int64(raw) - 1<<uint(bits-1)
+		} else {
+			v = int64(raw<<uint(64-bits)) >> uint(64-bits)
+		}
+		set(i, rescaleInt(v, bits, bitDepth))
+	}
+}
+
+// EncodePCM appends the samples of src, encoded using enc, to dst and
+// returns the extended slice. Samples are converted from the buffer's data
+// type as needed, see the PCMBuffer As* methods. The unused bits of LSB
+// aligned samples are sign extended.
+func EncodePCM(dst []byte, src *PCMBuffer, enc SampleEncoding) ([]byte, error) {
+	if src == nil {
+		return dst, ErrInvalidBuffer
+	}
+	if err := enc.checkCodec(); err != nil {
+		return dst, err
+	}
+	order := enc.byteOrder()
+	plain := enc.Encoding == EncodingSigned && enc.validBits() == enc.BitDepth
+	switch {
+	case enc.Encoding == EncodingFloat && enc.BitDepth == 32:
+		for _, s := range src.AsF32() {
+			dst = order.AppendUint32(dst, math.Float32bits(s))
+		}
+	case enc.Encoding == EncodingFloat:
+		for _, s := range src.AsF64() {
+			dst = order.AppendUint64(dst, math.Float64bits(s))
+		}
+	case enc.Encoding == EncodingUnsigned && enc.BitDepth == 8 && enc.validBits() == 8:
+		dst = append(dst, src.AsU8()...)
+	case plain && enc.BitDepth == 8:
+		for _, s := range src.AsI8() {
+			dst = append(dst, byte(s))
+		}
+	case plain && enc.BitDepth == 16:
+		for _, s := range src.AsI16() {
+			dst = order.AppendUint16(dst, uint16(s))
+		}
+	case plain && enc.BitDepth == 24 && enc.BigEndian:
+		dst = append(dst, src.AsI24BE()...)
+	case plain && enc.BitDepth == 24:
+		dst = append(dst, src.AsI24()...)
+	default:
+		dst = encodeInts(dst, src, enc)
+	}
+	return dst, nil
+}
+
+// encodeInts is the generic integer encoding path, handling any
+// signedness, endianness and alignment of the valid bits.
+func encodeInts(dst []byte, src *PCMBuffer, enc SampleEncoding) []byte {
+	size := enc.Size()
+	valid := enc.validBits()
+	bits := enc.BitDepth
+	shift := uint(bits - valid)
+	if enc.LSBAligned {
+		bits, shift = valid, 0
+	}
+	for _, v := range convertPCM[int64](src, valid) {
+		v <<= shift
+		if enc.Encoding == EncodingUnsigned {
+			v += 1 << uint(bits-1)
+		}
+		for k := 0; k < size; k++ {
+			s := uint(8 * k)
+			if enc.BigEndian {
+				s = uint(8 * (size - 1 - k))
+			}
+			dst = append(dst, byte(v>>s))
+		}
+	}
+	return dst
+}
+
+// resize returns a slice of n elements, reusing the storage of s if it is
+// large enough.
+func resize[T any](s []T, n int) []T {
+	if cap(s) >= n {
+		return s[:n]
+	}
+	return make([]T, n)
+}
@@ -0,0 +1,126 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodePCM(t *testing.T) {
+	tests := []struct {
+		name     string
+		enc      SampleEncoding
+		src      []byte
+		dataType PCMDataFormat
+		want     []float64
+	}{
+		{"s8", PCMS8, []byte{0x80, 0x40}, DataTypeI8, []float64{-1, 0.5}},
+		{"u8", PCMU8, []byte{0x00, 0xc0}, DataTypeU8, []float64{-1, 0.5}},
+		{"s16le", PCMS16LE, []byte{0x00, 0x80, 0x00, 0x40}, DataTypeI16, []float64{-1, 0.5}},
+		{"s16be", PCMS16BE, []byte{0x80, 0x00, 0x40, 0x00}, DataTypeI16, []float64{-1, 0.5}},
+		{"u16le", SampleEncoding{Encoding: EncodingUnsigned, BitDepth: 16}, []byte{0x00, 0x00, 0x00, 0xc0}, DataTypeI16, []float64{-1, 0.5}},
+		{"s24le", PCMS24LE, []byte{0, 0, 0x80, 0, 0, 0x40}, DataTypeI24, []float64{-1, 0.5}},
+		{"s24be", PCMS24BE, []byte{0x80, 0, 0, 0x40, 0, 0}, DataTypeI24BE, []float64{-1, 0.5}},
+		{"s24 in 32 lsb", PCMS24In32LE, []byte{0, 0, 0x80, 0xff, 0, 0, 0x40, 0}, DataTypeI32, []float64{-1, 0.5}},
+		{"s24 in 32 msb", SampleEncoding{BitDepth: 32, ValidBits: 24}, []byte{0, 0, 0, 0x80, 0, 0, 0, 0x40}, DataTypeI32, []float64{-1, 0.5}},
+		{"s20 in 24 lsb", SampleEncoding{BitDepth: 24, ValidBits: 20, LSBAligned: true}, []byte{0, 0, 0x08, 0, 0, 0x04}, DataTypeI24, []float64{-1, 0.5}},
+		{"s32be", PCMS32BE, []byte{0x80, 0, 0, 0, 0x40, 0, 0, 0}, DataTypeI32, []float64{-1, 0.5}},
+		{"f32le", PCMF32LE, []byte{0, 0, 0x80, 0xbf, 0, 0, 0, 0x3f}, DataTypeF32, []float64{-1, 0.5}},
+		{"f64be", PCMF64BE, []byte{0xbf, 0xf0, 0, 0, 0, 0, 0, 0, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0}, DataTypeF64, []float64{-1, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &PCMBuffer{Format: FormatMono44100}
+			n, err := DecodePCM(buf, tt.src, tt.enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(tt.src) {
+				t.Errorf("expected %d bytes to be consumed, got %d", len(tt.src), n)
+			}
+			if buf.DataType != tt.dataType {
+				t.Errorf("expected data type %d, got %d", tt.dataType, buf.DataType)
+			}
+			if got := buf.AsF64(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v got %v", tt.want, got)
+			}
+
+			out, err := EncodePCM(nil, buf, tt.enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.enc.LSBAligned {
+				// the unused bits are sign extended
+				return
+			}
+			if !bytes.Equal(out, tt.src) {
+				t.Errorf("expected the encoded bytes to be %x, got %x", tt.src, out)
+			}
+		})
+	}
+}
+
+func TestDecodePCM_Conversion(t *testing.T) {
+	buf := &PCMBuffer{Format: FormatStereo44100, DataType: DataTypeF64, F64: make([]float64, 0, 16)}
+	// the last 3 bytes don't make a full frame
+	src := []byte{0x00, 0x40, 0x00, 0xc0, 0x00, 0x20, 0x00, 0xe0, 0x01, 0x02, 0x03}
+	n, err := DecodePCM(buf, src, PCMS16LE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 8 {
+		t.Errorf("expected 8 bytes to be consumed, got %d", n)
+	}
+	if want := []float64{0.5, -0.5, 0.25, -0.25}; buf.DataType != DataTypeF64 || !reflect.DeepEqual(buf.F64, want) {
+		t.Errorf("expected %v got %+v", want, buf)
+	}
+	if buf.SourceBitDepth != 2 {
+		t.Errorf("expected the source bit depth to be 2, got %d", buf.SourceBitDepth)
+	}
+	if cap(buf.F64) != 16 {
+		t.Errorf("expected the storage to be reused, got a capacity of %d", cap(buf.F64))
+	}
+
+	i16 := &PCMBuffer{Format: FormatMono44100, DataType: DataTypeI16, I16: make([]int16, 4)}
+	if _, err := DecodePCM(i16, []byte{0, 0, 0x40, 0, 0, 0xc0}, PCMS24LE); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int16{16384, -16384}; !reflect.DeepEqual(i16.I16, want) || i16.SourceBitDepth != 2 {
+		t.Errorf("expected %v got %+v", want, i16)
+	}
+
+	out, err := EncodePCM([]byte{0xff}, buf, PCMU8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xff, 0xc0, 0x40, 0xa0, 0x60}; !bytes.Equal(out, want) {
+		t.Errorf("expected %x got %x", want, out)
+	}
+	out, _ = EncodePCM(nil, buf, PCMS24In32LE)
+	if want := []byte{0, 0, 0x40, 0, 0, 0, 0xc0, 0xff}; !bytes.Equal(out[:8], want) {
+		t.Errorf("expected %x got %x", want, out[:8])
+	}
+}
+
+func TestDecodePCM_Errors(t *testing.T) {
+	encodings := []SampleEncoding{
+		{BitDepth: 12},
+		{Encoding: EncodingFloat, BitDepth: 16},
+		{BitDepth: 16, ValidBits: 20},
+		{Encoding: 42, BitDepth: 8},
+		{Encoding: EncodingMuLaw, BitDepth: 8},
+		{Encoding: EncodingALaw, BitDepth: 16},
+	}
+	for _, enc := range encodings {
+		if _, err := DecodePCM(&PCMBuffer{}, []byte{0, 0}, enc); !errors.Is(err, ErrUnsupportedEncoding) {
+			t.Errorf("%+v: expected ErrUnsupportedEncoding, got %v", enc, err)
+		}
+		if _, err := EncodePCM(nil, &PCMBuffer{}, enc); !errors.Is(err, ErrUnsupportedEncoding) {
+			t.Errorf("%+v: expected ErrUnsupportedEncoding, got %v", enc, err)
+		}
+	}
+	if _, err := DecodePCM(nil, nil, PCMS16LE); err != ErrInvalidBuffer {
+		t.Errorf("expected ErrInvalidBuffer, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package audio
 
-import "math"
+import (
+	"encoding/binary"
+	"math"
+)
 
 // IntMaxSignedValue returns the max value of an integer
 // based on its memory size
@@ -20,87 +23,79 @@ func IntMaxSignedValue(b int) int {
 }
 
 // IEEEFloatToInt converts a 10 byte IEEE float into an int.
+// The fractional part is discarded and values out of the int range are
+// saturated, NaN being converted to 0. See ExtendedToFloat64.
 func IEEEFloatToInt(b [10]byte) int {
-	var i uint32
-	// Negative number
-	if (b[0] & 0x80) == 1 {
+	f := math.Trunc(ExtendedToFloat64(b))
+	switch {
+	case f != f:
 		return 0
+	case f >= -math.MinInt:
+		return math.MaxInt
+	case f <= math.MinInt:
+		return math.MinInt
 	}
-
-	// Less than 1
-	if b[0] <= 0x3F {
-		return 1
-	}
-
-	// Too big
-	if b[0] > 0x40 {
-		return 67108864
-	}
-
-	// Still too big
-	if b[0] == 0x40 && b[1] > 0x1C {
-		return 800000000
-	}
-
-	i = (uint32(b[2]) << 23) | (uint32(b[3]) << 15) | (uint32(b[4]) << 7) | (uint32(b[5]) >> 1)
-	i >>= (29 - uint32(b[1]))
-
-	return int(i)
+	return int(f)
 }
 
 // IntToIEEEFloat converts an int into a 10 byte IEEE float.
+// See Float64ToExtended.
 func IntToIEEEFloat(i int) [10]byte {
-	b := [10]byte{}
-	num := float64(i)
+	return Float64ToExtended(float64(i))
+}
 
-	var sign int
-	var expon int
-	var fMant, fsMant float64
-	var hiMant, loMant uint
+// ExtendedToFloat64 converts a big endian 80-bit IEEE 754 extended precision
+// float, as used by AIFF files for their sample rate, to a float64.
+// The mantissa is rounded to the float64 precision, values out of the
+// float64 range become infinities or zeros.
+func ExtendedToFloat64(b [10]byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]) & 0x7fff)
+	mant := binary.BigEndian.Uint64(b[2:10])
+	var f float64
+	switch {
+	case exp == 0x7fff && mant<<1 == 0:
+		f = math.Inf(1)
+	case exp == 0x7fff:
+		return math.NaN()
+	case exp == 0:
+		// denormal, the exponent is the one of the smallest normal value
+		f = math.Ldexp(float64(mant), 1-16383-63)
+	default:
+		f = math.Ldexp(float64(mant), exp-16383-63)
+	}
+	if b[0]&0x80 != 0 {
+		f = -f
+	}
+	return f
+}
 
-	if num < 0 {
+// Float64ToExtended converts a float64 to a big endian 80-bit IEEE 754
+// extended precision float. The conversion is exact, float64 denormals
+// being normalized.
+func Float64ToExtended(f float64) [10]byte {
+	var b [10]byte
+	var sign uint16
+	if math.Signbit(f) {
 		sign = 0x8000
-	} else {
-		sign = 0
+		f = -f
 	}
-
-	if num == 0 {
-		expon = 0
-		hiMant = 0
-		loMant = 0
-	} else {
-		fMant, expon = math.Frexp(num)
-		if (expon > 16384) || !(fMant < 1) { /* Infinity or NaN */
-			expon = sign | 0x7FFF
-			hiMant = 0
-			loMant = 0 /* infinity */
-		} else { /* Finite */
-			expon += 16382
-			if expon < 0 { /* denormalized */
-				fMant = math.Ldexp(fMant, expon)
-				expon = 0
-			}
-			expon |= sign
-			fMant = math.Ldexp(fMant, 32)
-			fsMant = math.Floor(fMant)
-			hiMant = uint(fsMant)
-			fMant = math.Ldexp(fMant-fsMant, 32)
-			fsMant = math.Floor(fMant)
-			loMant = uint(fsMant)
-		}
+	var exp uint16
+	var mant uint64
+	switch {
+	case math.IsNaN(f):
+		// quiet NaN
+		exp, mant = 0x7fff, 0xc000000000000000
+	case math.IsInf(f, 0):
+		exp, mant = 0x7fff, 0x8000000000000000
+	case f != 0:
+		// f = frac * 2^e with frac in [0.5, 1), the extended mantissa has
+		// an explicit integer bit
+		frac, e := math.Frexp(f)
+		exp = uint16(e - 1 + 16383)
+		mant = uint64(math.Ldexp(frac, 64))
 	}
-
-	b[0] = byte(expon >> 8)
-	b[1] = byte(expon)
-	b[2] = byte(hiMant >> 24)
-	b[3] = byte(hiMant >> 16)
-	b[4] = byte(hiMant >> 8)
-	b[5] = byte(hiMant)
-	b[6] = byte(loMant >> 24)
-	b[7] = byte(loMant >> 16)
-	b[8] = byte(loMant >> 8)
-	b[9] = byte(loMant)
-
+	binary.BigEndian.PutUint16(b[0:2], sign|exp)
+	binary.BigEndian.PutUint64(b[2:10], mant)
 	return b
 }
 
@@ -154,24 +149,78 @@ func Uint32toUint24Bytes(n uint32) []byte {
 // Int32toInt24LEBytes converts an int32 into a little endian 3 byte int24 representation
 func Int32toInt24LEBytes(n int32) []byte {
 	bytes := make([]byte, 3)
-	if (n & 0x800000) > 0 {
-		n |= ^0xffffff
-	}
-	bytes[2] = byte(n >> 16)
-	bytes[1] = byte(n >> 8)
-	bytes[0] = byte(n >> 0)
+	PutInt24LE(bytes, n)
 	return bytes
 }
 
 // Int32toInt24BEBytes converts an int32 into a big endian 3 byte int24 representation
 func Int32toInt24BEBytes(n int32) []byte {
 	bytes := make([]byte, 3)
-	if (n & 0x800000) > 0 {
-		n |= ^0xffffff
+	PutInt24BE(bytes, n)
+	return bytes
+}
+
+// PutInt24LE writes the 24 low bits of n into the first 3 bytes of b,
+// least significant byte first. It panics if b is too short.
+func PutInt24LE(b []byte, n int32) {
+	_ = b[2] // bounds check hint to compiler
+	b[0] = byte(n)
+	b[1] = byte(n >> 8)
+	b[2] = byte(n >> 16)
+}
+
+// PutInt24BE writes the 24 low bits of n into the first 3 bytes of b,
+// most significant byte first. It panics if b is too short.
+func PutInt24BE(b []byte, n int32) {
+	_ = b[2] // bounds check hint to compiler
+	b[0] = byte(n >> 16)
+	b[1] = byte(n >> 8)
+	b[2] = byte(n)
+}
+
+// IntToFloat64 normalizes an integer sample encoded on bitDepth bits to a
+// float in the [-1, 1) range by dividing it by 2^(bitDepth-1).
+// This is the conversion used by all the buffer implementations when going
+// from integer to float samples, it is exactly reversed by Float64ToInt.
+func IntToFloat64(s int, bitDepth int) float64 {
+	if bitDepth < 1 || bitDepth > 64 {
+		return 0
 	}
-	bytes[0] = byte(n >> 16)
-	bytes[1] = byte(n >> 8)
-	bytes[2] = byte(n >> 0)
+	return float64(s) / intScale(bitDepth)
+}
 
-	return bytes
+// Float64ToInt converts a normalized float sample to an integer sample
+// encoded on bitDepth bits. The sample is scaled by 2^(bitDepth-1), rounded to
+// the nearest integer and clipped to the range of a signed integer of
+// bitDepth bits. NaN values are converted to 0.
+func Float64ToInt(f float64, bitDepth int) int {
+	if bitDepth < 1 || bitDepth > 64 || f != f {
+		return 0
+	}
+	scale := intScale(bitDepth)
+	v := math.Round(f * scale)
+	if v >= scale {
+		return int(uint64(1)<<uint(bitDepth-1) - 1)
+	}
+	if v < -scale {
+		return -int(uint64(1) << uint(bitDepth-1))
+	}
+	return int(v)
+}
+
+// intScale returns 2^(bitDepth-1), the number of positive values
+// (including 0) a signed integer of bitDepth bits can represent.
+func intScale(bitDepth int) float64 {
+	return float64(uint64(1) << uint(bitDepth-1))
+}
+
+// DBToGain converts a gain in decibels to a linear gain.
+func DBToGain(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// GainToDB converts a linear gain or level to decibels, -Inf for 0. Levels
+// relative to the full scale, as normalized float samples, give dBFS values.
+func GainToDB(gain float64) float64 {
+	return 20 * math.Log10(math.Abs(gain))
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"math"
 	"testing"
+	"testing/quick"
 )
 
 func TestInt24BETo32(t *testing.T) {
@@ -170,8 +171,10 @@ func TestIntToIEEEFloat(t *testing.T) {
 		val2 int
 	}{
 		{name: "min", ret: [10]byte{0x3f, 0xff, 0x80}, val: 1, val2: 1},
-		{name: "max", ret: [10]byte{0x40, 0x3e, 0x80}, val: math.MaxInt64, val2: 800000000}, // IEEEFloatToInt truncates
+		{name: "max", ret: [10]byte{0x40, 0x3e, 0x80}, val: math.MaxInt64, val2: math.MaxInt64},
 		{name: "random", ret: [10]byte{0x40, 0x15, 0xbb, 0x97, 0xda}, val: 6147053, val2: 6147053},
+		{name: "negative", ret: [10]byte{0xc0, 0x0e, 0xac, 0x44}, val: -44100, val2: -44100},
+		{name: "zero", ret: [10]byte{}, val: 0, val2: 0},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -190,3 +193,136 @@ func TestIntToIEEEFloat(t *testing.T) {
 		})
 	}
 }
+
+func TestFloat64ToInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        float64
+		bitDepth int
+		want     int
+	}{
+		{"zero", 0, 16, 0},
+		{"full scale", 1, 16, 32767},
+		{"negative full scale", -1, 16, -32768},
+		{"half", 0.5, 24, 4194304},
+		{"round up", 1.5 / 128, 8, 2},
+		{"round down", -1.4 / 128, 8, -1},
+		{"clip", 3, 8, 127},
+		{"negative clip", -3, 32, -2147483648},
+		{"64 bit", 1, 64, math.MaxInt64},
+		{"invalid bit depth", 0.5, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Float64ToInt(tt.f, tt.bitDepth); got != tt.want {
+				t.Errorf("Float64ToInt(%v, %d) = %d, want %d", tt.f, tt.bitDepth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntToFloat64(t *testing.T) {
+	for _, bitDepth := range []int{8, 16, 24, 32} {
+		max := IntMaxSignedValue(bitDepth)
+		for _, s := range []int{-max - 1, -max, -1, 0, 1, max} {
+			f := IntToFloat64(s, bitDepth)
+			if f < -1 || f >= 1 {
+				t.Errorf("IntToFloat64(%d, %d) = %v, out of the [-1, 1) range", s, bitDepth, f)
+			}
+			if got := Float64ToInt(f, bitDepth); got != s {
+				t.Errorf("Float64ToInt(IntToFloat64(%d, %d)) = %d", s, bitDepth, got)
+			}
+		}
+	}
+}
+
+func TestPutInt24(t *testing.T) {
+	tests := []struct {
+		in     int32
+		le, be []byte
+	}{
+		{0, []byte{0, 0, 0}, []byte{0, 0, 0}},
+		{8388607, []byte{0xff, 0xff, 0x7f}, []byte{0x7f, 0xff, 0xff}},
+		{-8388608, []byte{0, 0, 0x80}, []byte{0x80, 0, 0}},
+		{-2, []byte{0xfe, 0xff, 0xff}, []byte{0xff, 0xff, 0xfe}},
+	}
+	for _, tt := range tests {
+		le, be := make([]byte, 3), make([]byte, 3)
+		PutInt24LE(le, tt.in)
+		PutInt24BE(be, tt.in)
+		if !bytes.Equal(le, tt.le) || !bytes.Equal(be, tt.be) {
+			t.Errorf("%d: expected %x/%x got %x/%x", tt.in, tt.le, tt.be, le, be)
+		}
+		if Int24LETo32(le) != tt.in || Int24BETo32(be) != tt.in {
+			t.Errorf("%d didn't round trip", tt.in)
+		}
+	}
+}
+
+func TestExtendedToFloat64(t *testing.T) {
+	tests := []struct {
+		name string
+		in   [10]byte
+		want float64
+	}{
+		{"44100", [10]byte{0x40, 0x0e, 0xac, 0x44}, 44100},
+		{"11025.5", [10]byte{0x40, 0x0c, 0xac, 0x46}, 11025.5},
+		{"352800", [10]byte{0x40, 0x11, 0xac, 0x44}, 352800},
+		{"-0.75", [10]byte{0xbf, 0xfe, 0xc0}, -0.75},
+		{"+inf", [10]byte{0x7f, 0xff, 0x80}, math.Inf(1)},
+		{"-inf", [10]byte{0xff, 0xff, 0x80}, math.Inf(-1)},
+		{"overflow", [10]byte{0x7f, 0xfe, 0x80}, math.Inf(1)},
+		{"underflow", [10]byte{0x00, 0x01, 0x80}, 0},
+		{"float64 denormal", [10]byte{0x3b, 0xcd, 0x80}, math.SmallestNonzeroFloat64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtendedToFloat64(tt.in); got != tt.want {
+				t.Errorf("ExtendedToFloat64(%x) = %v, want %v", tt.in, got, tt.want)
+			}
+			if math.IsInf(tt.want, 0) || tt.want == 0 {
+				return
+			}
+			if got := Float64ToExtended(tt.want); got != tt.in {
+				t.Errorf("Float64ToExtended(%v) = %x, want %x", tt.want, got, tt.in)
+			}
+		})
+	}
+
+	if !math.IsNaN(ExtendedToFloat64(Float64ToExtended(math.NaN()))) {
+		t.Error("expected NaN to round trip")
+	}
+	if got := ExtendedToFloat64(Float64ToExtended(math.Copysign(0, -1))); got != 0 || !math.Signbit(got) {
+		t.Errorf("expected -0 to round trip, got %v", got)
+	}
+	if got := IEEEFloatToInt([10]byte{0x40, 0x0c, 0xac, 0x46}); got != 11025 {
+		t.Errorf("expected the fractional part to be discarded, got %d", got)
+	}
+	if got := IEEEFloatToInt([10]byte{0xff, 0xff, 0x80}); got != math.MinInt64 {
+		t.Errorf("expected -inf to saturate, got %d", got)
+	}
+}
+
+func TestExtendedRoundTrip(t *testing.T) {
+	float := func(f float64) bool {
+		got := ExtendedToFloat64(Float64ToExtended(f))
+		return got == f || (f != f && got != got)
+	}
+	if err := quick.Check(float, nil); err != nil {
+		t.Error(err)
+	}
+	bits := func(u uint64) bool {
+		f := math.Float64frombits(u)
+		got := ExtendedToFloat64(Float64ToExtended(f))
+		return math.Float64bits(got) == u || (f != f && got != got)
+	}
+	if err := quick.Check(bits, nil); err != nil {
+		t.Error(err)
+	}
+	ints := func(i int32) bool {
+		return IEEEFloatToInt(IntToIEEEFloat(int(i))) == int(i)
+	}
+	if err := quick.Check(ints, nil); err != nil {
+		t.Error(err)
+	}
+}
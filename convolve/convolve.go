@@ -0,0 +1,266 @@
+/*
+Package convolve applies long impulse responses, such as room reverbs or
+cabinet simulations, to audio buffers.
+
+A Convolver uses a uniformly partitioned overlap-save algorithm: the impulse
+response is split into blocks whose spectra are multiplied with the spectra
+of the past input blocks. The cost per sample grows with the logarithm of
+the block size and linearly with the number of partitions, making it far
+cheaper than a direct form FIR filter (see filter.FIR) for long impulse
+responses. The output isn't delayed: the current, partially filled, block is
+convolved on every call.
+*/
+package convolve
+
+import (
+	"fmt"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/fft"
+)
+
+// DefaultBlockSize is the block size used when none is given to New.
+const DefaultBlockSize = 256
+
+// Convolver convolves a stream with an impulse response, keeping its state
+// between calls to Process.
+type Convolver struct {
+	numChannels int
+	sampleRate  int
+	block       int
+	plan        *fft.Plan
+	irFrames    int
+	// paths holds the spectra of the partitions of the impulse response
+	// from each input channel to each output channel, nil when an input
+	// doesn't feed an output.
+	paths [][][][]complex128
+	// prev and cur hold the previous and the current input blocks of each
+	// channel, pos being the number of frames of the current block.
+	prev, cur [][]float64
+	pos       int
+	// history holds the spectra of the last full input blocks of each
+	// channel, the most recent at index last.
+	history [][][]complex128
+	last    int
+	// rest holds, for each output channel, the contribution of the past
+	// input blocks to the current output block.
+	rest [][]float64
+	// scratch spectra
+	in  [][]complex128
+	out []complex128
+}
+
+// New returns a convolver applying the impulse response ir, which can be any
+// buffer, to a stream of the given format. The channels of ir are used as
+// follows:
+//
+//   - a single channel is applied to every channel of the stream,
+//   - one channel per stream channel is applied to the matching channel,
+//   - numChannels² channels describe the response from each input channel
+//     to each output channel (true stereo for two channels), channel
+//     in*numChannels+out going from the input in to the output out: LL, LR,
+//     RL, RR for stereo.
+//
+// blockSize is rounded up to a power of two and defaults to DefaultBlockSize
+// if not positive. Larger blocks are cheaper per sample but more expensive
+// per call. It returns an error wrapping audio.ErrFormatMismatch if the
+// channels or the sample rate of ir don't match the format.
+func New(format *audio.Format, ir audio.Buffer, blockSize int) (*Convolver, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	blockSize = fft.NextPowerOfTwo(blockSize)
+	plan, err := fft.NewPlan(2 * blockSize)
+	if err != nil {
+		return nil, err
+	}
+	nc := format.NumChannels
+	irBuf := ir.AsFloatBuffer()
+	irChannels := 1
+	if f := irBuf.Format; f != nil {
+		if f.NumChannels > 0 {
+			irChannels = f.NumChannels
+		}
+		if f.SampleRate != 0 && f.SampleRate != format.SampleRate {
+			return nil, fmt.Errorf("%w: %d Hz impulse response for a %d Hz stream", audio.ErrFormatMismatch, f.SampleRate, format.SampleRate)
+		}
+	}
+	c := &Convolver{
+		numChannels: nc,
+		sampleRate:  format.SampleRate,
+		block:       blockSize,
+		plan:        plan,
+		irFrames:    len(irBuf.Data) / irChannels,
+		paths:       make([][][][]complex128, nc),
+	}
+	for in := range c.paths {
+		c.paths[in] = make([][][]complex128, nc)
+	}
+	channel := func(i int) [][]complex128 {
+		return c.partition(irBuf.Data, irChannels, i)
+	}
+	switch irChannels {
+	case 1:
+		h := channel(0)
+		for i := 0; i < nc; i++ {
+			c.paths[i][i] = h
+		}
+	case nc:
+		for i := 0; i < nc; i++ {
+			c.paths[i][i] = channel(i)
+		}
+	case nc * nc:
+		for in := 0; in < nc; in++ {
+			for out := 0; out < nc; out++ {
+				c.paths[in][out] = channel(in*nc + out)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: %d channel impulse response for a %d channel stream", audio.ErrFormatMismatch, irChannels, nc)
+	}
+
+	partitions := max(1, (c.irFrames+blockSize-1)/blockSize)
+	c.prev, c.cur = make([][]float64, nc), make([][]float64, nc)
+	c.history, c.in = make([][][]complex128, nc), make([][]complex128, nc)
+	c.rest = make([][]float64, nc)
+	for ch := 0; ch < nc; ch++ {
+		c.prev[ch], c.cur[ch] = make([]float64, blockSize), make([]float64, blockSize)
+		c.history[ch] = make([][]complex128, partitions)
+		for p := range c.history[ch] {
+			c.history[ch][p] = make([]complex128, 2*blockSize)
+		}
+		c.in[ch] = make([]complex128, 2*blockSize)
+		c.rest[ch] = make([]float64, blockSize)
+	}
+	c.out = make([]complex128, 2*blockSize)
+	return c, nil
+}
+
+// partition returns the spectra of the blocks of the channel ch of the
+// interleaved impulse response data.
+func (c *Convolver) partition(data []float64, numChannels, ch int) [][]complex128 {
+	frames := len(data) / numChannels
+	var spectra [][]complex128
+	for start := 0; start < frames || start == 0; start += c.block {
+		s := make([]complex128, 2*c.block)
+		for i := 0; i < c.block && start+i < frames; i++ {
+			s[i] = complex(data[(start+i)*numChannels+ch], 0)
+		}
+		c.plan.Forward(s)
+		spectra = append(spectra, s)
+	}
+	return spectra
+}
+
+// TailFrames returns the number of frames the output keeps ringing after the
+// end of the input, that is to say the length of the impulse response minus
+// one. Process that many silent frames to get the full tail.
+func (c *Convolver) TailFrames() int { return max(0, c.irFrames-1) }
+
+// Process convolves the frames of buf in place, see audio.TransformFloat. It
+// returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the sample rate of the convolver.
+func (c *Convolver) Process(buf audio.Buffer) error {
+	nc := c.numChannels
+	if err := audio.CheckFormat(buf, nc, c.sampleRate); err != nil {
+		return err
+	}
+	return audio.TransformFloat(buf, func(samples []float64, _ int) {
+		frames := len(samples) / nc
+		for start := 0; start < frames; {
+			n := min(frames-start, c.block-c.pos)
+			c.processSegment(samples[start*nc:(start+n)*nc], n)
+			start += n
+		}
+	})
+}
+
+// processSegment convolves n interleaved frames that fit in the current
+// block.
+func (c *Convolver) processSegment(samples []float64, n int) {
+	nc, b := c.numChannels, c.block
+	for ch := 0; ch < nc; ch++ {
+		cur := c.cur[ch]
+		for i := 0; i < n; i++ {
+			cur[c.pos+i] = samples[i*nc+ch]
+		}
+		// spectrum of the previous block followed by the current one,
+		// the frames still to come being zeros
+		x := c.in[ch]
+		for i, v := range c.prev[ch] {
+			x[i] = complex(v, 0)
+		}
+		for i, v := range cur {
+			x[b+i] = complex(v, 0)
+		}
+		c.plan.Forward(x)
+	}
+	for out := 0; out < nc; out++ {
+		clear(c.out)
+		for in := 0; in < nc; in++ {
+			if h := c.paths[in][out]; h != nil {
+				multiplyAdd(c.out, c.in[in], h[0])
+			}
+		}
+		c.plan.Inverse(c.out)
+		rest := c.rest[out]
+		for i := 0; i < n; i++ {
+			samples[i*nc+out] = real(c.out[b+c.pos+i]) + rest[c.pos+i]
+		}
+	}
+	c.pos += n
+	if c.pos == b {
+		c.nextBlock()
+	}
+}
+
+// nextBlock records the spectrum of the completed block and computes the
+// contribution of the past blocks to the next output block.
+func (c *Convolver) nextBlock() {
+	nc, b := c.numChannels, c.block
+	partitions := len(c.history[0])
+	c.last = (c.last + 1) % partitions
+	for ch := 0; ch < nc; ch++ {
+		copy(c.history[ch][c.last], c.in[ch])
+		c.prev[ch], c.cur[ch] = c.cur[ch], c.prev[ch]
+		clear(c.cur[ch])
+	}
+	for out := 0; out < nc; out++ {
+		clear(c.out)
+		for in := 0; in < nc; in++ {
+			h := c.paths[in][out]
+			for p := 1; p < len(h); p++ {
+				x := c.history[in][(c.last-p+1+partitions)%partitions]
+				multiplyAdd(c.out, x, h[p])
+			}
+		}
+		c.plan.Inverse(c.out)
+		for i := range c.rest[out] {
+			c.rest[out][i] = real(c.out[b+i])
+		}
+	}
+	c.pos = 0
+}
+
+// Reset clears the state of the convolver, as if it never processed samples.
+func (c *Convolver) Reset() {
+	for ch := range c.prev {
+		clear(c.prev[ch])
+		clear(c.cur[ch])
+		clear(c.rest[ch])
+		for _, x := range c.history[ch] {
+			clear(x)
+		}
+	}
+	c.pos, c.last = 0, 0
+}
+
+// multiplyAdd adds the products of the spectra a and b to dst.
+func multiplyAdd(dst, a, b []complex128) {
+	for i := range dst {
+		dst[i] += a[i] * b[i]
+	}
+}
@@ -0,0 +1,108 @@
+package convolve
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+// direct returns the convolution of the channel ch of the interleaved x with
+// the channel hch of the interleaved h, truncated to the length of x.
+func direct(x []float64, nc, ch int, h []float64, hnc, hch int) []float64 {
+	frames, taps := len(x)/nc, len(h)/hnc
+	y := make([]float64, frames)
+	for n := range y {
+		for k := 0; k < taps && k <= n; k++ {
+			y[n] += h[k*hnc+hch] * x[(n-k)*nc+ch]
+		}
+	}
+	return y
+}
+
+func random(rng *rand.Rand, n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rng.Float64()*2 - 1
+	}
+	return x
+}
+
+func TestConvolver(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	format := &audio.Format{NumChannels: 2, SampleRate: 48000}
+	tests := []struct {
+		name       string
+		irChannels int
+		// want returns the expected output channel out
+		want func(x, h []float64, out int) []float64
+	}{
+		{"mono", 1, func(x, h []float64, out int) []float64 {
+			return direct(x, 2, out, h, 1, 0)
+		}},
+		{"per channel", 2, func(x, h []float64, out int) []float64 {
+			return direct(x, 2, out, h, 2, out)
+		}},
+		{"true stereo", 4, func(x, h []float64, out int) []float64 {
+			y := direct(x, 2, 0, h, 4, out)
+			for i, v := range direct(x, 2, 1, h, 4, 2+out) {
+				y[i] += v
+			}
+			return y
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := random(rng, 300*tt.irChannels)
+			ir := &audio.Float32Buffer{Format: &audio.Format{NumChannels: tt.irChannels}, Data: make([]float32, len(h))}
+			for i, v := range h {
+				ir.Data[i] = float32(v)
+				h[i] = float64(ir.Data[i])
+			}
+			c, err := New(format, ir, 50)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.TailFrames() != 299 {
+				t.Errorf("expected a 299 frame tail, got %d", c.TailFrames())
+			}
+			x := random(rng, 2*1000)
+			buf := &audio.FloatBuffer{Format: format, Data: append([]float64(nil), x...)}
+			// stream the input in uneven chunks
+			for _, chunk := range buf.Split(37) {
+				if err := c.Process(chunk); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for out := 0; out < 2; out++ {
+				want := tt.want(x, h, out)
+				for i, w := range want {
+					if got := buf.Data[2*i+out]; math.Abs(got-w) > 1e-9 {
+						t.Fatalf("channel %d frame %d: expected %v, got %v", out, i, w, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConvolver_Errors(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: 48000}
+	ir := &audio.FloatBuffer{Format: &audio.Format{NumChannels: 3}, Data: make([]float64, 30)}
+	if _, err := New(format, ir, 0); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	ir = &audio.FloatBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: 44100}, Data: make([]float64, 30)}
+	if _, err := New(format, ir, 0); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	c, err := New(format, &audio.FloatBuffer{Data: []float64{1}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Process(&audio.FloatBuffer{Format: audio.FormatMono44100}); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+}
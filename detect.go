@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"math"
+	"math/bits"
+)
+
+// standardBitDepths are the bit depths DetectBitDepth can report, in
+// increasing order.
+var standardBitDepths = []int{8, 16, 24, 32, 64}
+
+// BitDepthEstimate is the result of DetectBitDepth.
+type BitDepthEstimate struct {
+	// BitDepth is the estimated bit depth of the source samples, 0 if it
+	// couldn't be estimated (silence or samples not matching any integer
+	// bit depth).
+	BitDepth int
+	// Confidence, between 0 and 1, tells how reliable the estimate is.
+	Confidence float64
+}
+
+// DetectBitDepth analyzes the samples of b and estimates the bit depth they
+// were encoded with. The estimate is never applied to the buffer, callers
+// can set it as SourceBitDepth if they trust it.
+//
+// Integer samples are analyzed by their range, considering both the lowest
+// and the highest sample. The confidence is the peak amplitude relative to
+// the full scale of the estimated bit depth: a quiet signal fits a lower bit
+// depth than the one it was encoded with and gets a low confidence.
+//
+// Float samples are analyzed by their quantization: the estimate is the
+// lowest bit depth whose grid (multiples of 1/2^(N-1)) holds all the
+// samples. The confidence grows with the number of non-zero samples, each
+// of them lying on a finer grid by chance being unlikely.
+func DetectBitDepth(b Buffer) BitDepthEstimate {
+	switch b := b.(type) {
+	case nil:
+		return BitDepthEstimate{}
+	case *IntBuffer:
+		return detectIntBitDepth(b.Data)
+	case *FloatBuffer:
+		return detectFloatBitDepth(b.Data)
+	case *Float32Buffer:
+		return detectFloatBitDepth(b.Data)
+	case *PCMBuffer:
+		switch b.DataType {
+		case DataTypeI8:
+			return detectIntBitDepth(b.I8)
+		case DataTypeI16:
+			return detectIntBitDepth(b.I16)
+		case DataTypeI32:
+			return detectIntBitDepth(b.I32)
+		case DataTypeU8, DataTypeI24, DataTypeI24BE:
+			return detectIntBitDepth(convertPCM[int32](b, b.DataType.containerBitDepth()))
+		case DataTypeF32:
+			return detectFloatBitDepth(b.F32)
+		case DataTypeF64:
+			return detectFloatBitDepth(b.F64)
+		}
+		return BitDepthEstimate{}
+	}
+	return detectFloatBitDepth(b.AsFloatBuffer().Data)
+}
+
+// detectIntBitDepth estimates the bit depth of integer samples from their
+// range, see DetectBitDepth.
+func detectIntBitDepth[T Sample](data []T) BitDepthEstimate {
+	if len(data) == 0 {
+		return BitDepthEstimate{}
+	}
+	lo, hi := int64(data[0]), int64(data[0])
+	for _, s := range data[1:] {
+		lo = min(lo, int64(s))
+		hi = max(hi, int64(s))
+	}
+	if lo == 0 && hi == 0 {
+		return BitDepthEstimate{}
+	}
+	// a signed N-bit integer holds [-2^(N-1), 2^(N-1)-1]
+	needed := bits.Len64(uint64(max(hi, 0)))
+	if lo < 0 {
+		needed = max(needed, bits.Len64(uint64(^lo)))
+	}
+	needed++
+	bitDepth := standardBitDepths[len(standardBitDepths)-1]
+	for _, d := range standardBitDepths {
+		if d >= needed {
+			bitDepth = d
+			break
+		}
+	}
+	peak := max(float64(hi), -float64(lo))
+	return BitDepthEstimate{
+		BitDepth:   bitDepth,
+		Confidence: math.Min(1, peak/intScale(bitDepth)),
+	}
+}
+
+// detectFloatBitDepth estimates the bit depth of float samples from their
+// quantization, see DetectBitDepth.
+func detectFloatBitDepth[T Sample](data []T) BitDepthEstimate {
+	nonZero := 0
+	for _, s := range data {
+		if s != 0 {
+			nonZero++
+		}
+	}
+	if nonZero == 0 {
+		return BitDepthEstimate{}
+	}
+	prev := 0
+	for _, bitDepth := range standardBitDepths[:4] {
+		if onGrid(data, intScale(bitDepth)) {
+			// each non-zero sample has a 2^-(bitDepth-prev) chance of
+			// lying on this grid if it was encoded with more bits.
+			miss := math.Pow(2, -float64((bitDepth-prev)*nonZero))
+			return BitDepthEstimate{BitDepth: bitDepth, Confidence: 1 - miss}
+		}
+		prev = bitDepth
+	}
+	return BitDepthEstimate{}
+}
+
+// onGrid returns true if all the samples are multiples of 1/scale and in the
+// [-1, 1) range.
+func onGrid[T Sample](data []T, scale float64) bool {
+	for _, s := range data {
+		v := float64(s) * scale
+		if v != math.Trunc(v) || v < -scale || v >= scale {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package audio
+
+import "testing"
+
+func TestDetectBitDepth(t *testing.T) {
+	tests := []struct {
+		name          string
+		buf           Buffer
+		bitDepth      int
+		minConfidence float64
+		maxConfidence float64
+	}{
+		{"nil", nil, 0, 0, 0},
+		{"silence", &IntBuffer{Data: []int{0, 0, 0}}, 0, 0, 0},
+		{"full scale 16-bit", &IntBuffer{Data: []int{32767, -32768}}, 16, 1, 1},
+		{"all positive 16-bit", &IntBuffer{Data: []int{32767, 1}}, 16, 0.99, 1},
+		{"all positive quiet", &IntBuffer{Data: []int{100, 200}}, 16, 0, 0.01},
+		{"all negative 16-bit", &IntBuffer{Data: []int{-20000, -300}}, 16, 0.6, 0.62},
+		{"quiet 24-bit", &PCMBuffer{I32: []int32{40000, -10}, DataType: DataTypeI32}, 24, 0, 0.01},
+		{"8-bit", &PCMBuffer{I8: []int8{-128, 12}, DataType: DataTypeI8}, 8, 1, 1},
+		{"unsigned 8-bit", &PCMBuffer{U8: []uint8{0, 128, 200}, DataType: DataTypeU8}, 8, 1, 1},
+		{"16-bit grid", &FloatBuffer{Data: []float64{1.0 / 32768, -0.5, 3.0 / 32768}}, 16, 0.99, 1},
+		{"24-bit grid", &Float32Buffer{Data: []float32{1.0 / (1 << 23), 0.25}}, 24, 0.99, 1},
+		{"native float", &FloatBuffer{Data: []float64{0.1, 0.2}}, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectBitDepth(tt.buf)
+			if got.BitDepth != tt.bitDepth {
+				t.Errorf("expected %d bits, got %d", tt.bitDepth, got.BitDepth)
+			}
+			if got.Confidence < tt.minConfidence || got.Confidence > tt.maxConfidence {
+				t.Errorf("expected a confidence in [%v, %v], got %v", tt.minConfidence, tt.maxConfidence, got.Confidence)
+			}
+		})
+	}
+}
+
+func TestConversions_NoGuessing(t *testing.T) {
+	// a quiet buffer isn't treated as 8-bit and isn't modified
+	ib := &IntBuffer{Format: FormatMono44100, Data: []int{100, -100}}
+	fb := ib.AsFloat32Buffer()
+	if ib.SourceBitDepth != 0 {
+		t.Errorf("expected the source to be left untouched, got SourceBitDepth %d", ib.SourceBitDepth)
+	}
+	if fb.SourceBitDepth != DefaultBitDepth || fb.Data[0] != 100.0/32768 {
+		t.Errorf("expected samples scaled from %d bits, got %v (%d bits)", DefaultBitDepth, fb.Data, fb.SourceBitDepth)
+	}
+	// PCM int stores default to their container width
+	pb := &PCMBuffer{Format: FormatMono44100, I32: []int32{1 << 30}, DataType: DataTypeI32}
+	if got := pb.AsFloatBuffer(); got.Data[0] != 0.5 || got.SourceBitDepth != 32 {
+		t.Errorf("expected 0.5 from 32 bits, got %v (%d bits)", got.Data[0], got.SourceBitDepth)
+	}
+	if pb.SourceBitDepth != 0 {
+		t.Errorf("expected the source to be left untouched, got SourceBitDepth %d", pb.SourceBitDepth)
+	}
+}
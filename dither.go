@@ -0,0 +1,219 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// DitherType describes the noise added to the samples before they get
+// quantized to a lower bit depth.
+type DitherType uint8
+
+const (
+	// NoDither rounds the samples to the nearest integer.
+	NoDither DitherType = iota
+	// RectangularDither adds uniformly distributed noise of 1 LSB peak to peak.
+	RectangularDither
+	// TriangularDither adds noise with a triangular probability density
+	// function (TPDF) of 2 LSB peak to peak. It fully decorrelates the
+	// quantization error from the signal and is the usual choice.
+	TriangularDither
+	// HighPassTriangularDither adds TPDF noise obtained by differentiating
+	// uniform noise, moving most of the dither energy to high frequencies
+	// where it is less audible.
+	HighPassTriangularDither
+)
+
+// Noise shaping filters to use with a Ditherer. The coefficients are applied
+// to the previous quantization errors, most recent first.
+var (
+	// NoiseShapingFirstOrder is a first-order error feedback filter, it
+	// moves the quantization noise towards high frequencies (+6 dB/octave).
+	NoiseShapingFirstOrder = []float64{1}
+	// NoiseShapingSecondOrder is a second-order error feedback filter
+	// (+12 dB/octave).
+	NoiseShapingSecondOrder = []float64{2, -1}
+	// NoiseShapingFWeighted is the 9 taps F-weighted filter from Wannamaker's
+	// "Psychoacoustically optimal noise shaping", designed for 44.1kHz
+	// material. It moves the noise to where the ear is the least sensitive.
+	NoiseShapingFWeighted = []float64{2.412, -3.370, 3.937, -4.174, 3.353, -2.205, 1.281, -0.569, 0.0847}
+)
+
+// Ditherer quantizes normalized float samples to integers, adding dither
+// noise and optionally shaping the quantization noise. It keeps some state
+// per channel and should therefore be used for a single stream.
+// A nil Ditherer rounds samples like Float64ToInt.
+type Ditherer struct {
+	// Type is the type of dither noise added before quantization.
+	Type DitherType
+	// NoiseShaping holds the coefficients of the error feedback filter used
+	// to shape the quantization noise, nil disables noise shaping.
+	NoiseShaping []float64
+
+	rng      *rand.Rand
+	channels []ditherState
+}
+
+// ditherState is the per channel state of a Ditherer.
+type ditherState struct {
+	// prevNoise is the last uniform noise value (high-pass dither)
+	prevNoise float64
+	// errors holds the previous quantization errors, most recent first.
+	errors []float64
+}
+
+// NewDitherer returns a Ditherer adding noise of the given type and
+// using a copy of the noiseShaping error feedback filter (nil for none). The
+// seed makes the generated noise, and therefore the output, reproducible.
+func NewDitherer(t DitherType, noiseShaping []float64, seed int64) *Ditherer {
+	return &Ditherer{
+		Type:         t,
+		NoiseShaping: append([]float64(nil), noiseShaping...),
+		rng:          rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Reset clears the state of the ditherer, the noise sequence isn't reset.
+func (d *Ditherer) Reset() {
+	d.channels = nil
+}
+
+// Quantize converts the normalized sample s from the given channel to an
+// integer encoded on bitDepth bits. The output is clipped to the range of a
+// signed integer of bitDepth bits. Quantize panics if channel is negative.
+func (d *Ditherer) Quantize(s float64, channel, bitDepth int) int {
+	if d == nil {
+		return Float64ToInt(s, bitDepth)
+	}
+	if bitDepth < 1 || bitDepth > 64 {
+		return 0
+	}
+	if s != s {
+		s = 0
+	}
+	st := d.state(channel)
+	scale := intScale(bitDepth)
+	x := s * scale
+	for i, c := range d.NoiseShaping {
+		x -= c * st.errors[i]
+	}
+	v := math.Round(x + d.noise(st))
+	if len(st.errors) > 0 {
+		copy(st.errors[1:], st.errors)
+		// the error isn't affected by clipping which would make the
+		// feedback loop unstable.
+		st.errors[0] = v - x
+	}
+	if v >= scale {
+		return int(uint64(1)<<uint(bitDepth-1) - 1)
+	}
+	if v < -scale {
+		return -int(uint64(1) << uint(bitDepth-1))
+	}
+	return int(v)
+}
+
+// state returns the state of the given channel, clearing its quantization
+// errors if the noise shaping filter changed length.
+func (d *Ditherer) state(channel int) *ditherState {
+	if channel < 0 {
+		panic(fmt.Sprintf("audio: dithering the negative channel %d", channel))
+	}
+	for len(d.channels) <= channel {
+		d.channels = append(d.channels, ditherState{})
+	}
+	st := &d.channels[channel]
+	if len(st.errors) != len(d.NoiseShaping) {
+		st.errors = make([]float64, len(d.NoiseShaping))
+	}
+	return st
+}
+
+// noise returns the next dither noise value in LSB.
+func (d *Ditherer) noise(st *ditherState) float64 {
+	if d.rng == nil {
+		d.rng = rand.New(rand.NewSource(1))
+	}
+	switch d.Type {
+	case RectangularDither:
+		return d.rng.Float64() - 0.5
+	case TriangularDither:
+		return d.rng.Float64() - d.rng.Float64()
+	case HighPassTriangularDither:
+		r := d.rng.Float64()
+		n := r - st.prevNoise
+		st.prevNoise = r
+		return n
+	}
+	return 0
+}
+
+// AsDitheredIntBuffer returns a copy of this buffer with data quantized to
+// ints of bitDepth bits using the ditherer d.
+func (buf *FloatBuffer) AsDitheredIntBuffer(bitDepth int, d *Ditherer) *IntBuffer {
+	newB := &IntBuffer{SourceBitDepth: bitDepth}
+	newB.Data = make([]int, len(buf.Data))
+	nc := numChannels(buf.Format)
+	for i := 0; i < len(buf.Data); i++ {
+		newB.Data[i] = d.Quantize(buf.Data[i], i%nc, bitDepth)
+	}
+	newB.Format = buf.Format.clone()
+	return newB
+}
+
+// AsDitheredIntBuffer returns a copy of this buffer with data quantized to
+// ints of bitDepth bits using the ditherer d.
+func (buf *Float32Buffer) AsDitheredIntBuffer(bitDepth int, d *Ditherer) *IntBuffer {
+	newB := &IntBuffer{SourceBitDepth: bitDepth}
+	newB.Data = make([]int, len(buf.Data))
+	nc := numChannels(buf.Format)
+	for i := 0; i < len(buf.Data); i++ {
+		newB.Data[i] = d.Quantize(float64(buf.Data[i]), i%nc, bitDepth)
+	}
+	newB.Format = buf.Format.clone()
+	return newB
+}
+
+// SwitchPrimaryTypeDithered works like SwitchPrimaryType but uses the
+// ditherer d when the conversion reduces the resolution of the samples
+// (float to int or int to a lower bit depth int).
+func (b *PCMBuffer) SwitchPrimaryTypeDithered(t PCMDataFormat, d *Ditherer) {
+	if b == nil || t == b.DataType {
+		return
+	}
+	bitDepth := b.storeBitDepth(t)
+	if !t.isInt() || (b.isInt() && b.intBitDepth() <= bitDepth) {
+		b.SwitchPrimaryType(t)
+		return
+	}
+	src := b.AsF64()
+	nc := numChannels(b.Format)
+	b.I8, b.I16, b.I32, b.F32, b.F64 = nil, nil, nil, nil, nil
+	b.U8, b.I24 = nil, nil
+	b.DataType = t
+	switch t {
+	case DataTypeI8:
+		b.I8 = make([]int8, len(src))
+		for i, s := range src {
+			b.I8[i] = int8(d.Quantize(s, i%nc, bitDepth))
+		}
+	case DataTypeI16:
+		b.I16 = make([]int16, len(src))
+		for i, s := range src {
+			b.I16[i] = int16(d.Quantize(s, i%nc, bitDepth))
+		}
+	case DataTypeI32:
+		b.I32 = make([]int32, len(src))
+		for i, s := range src {
+			b.I32[i] = int32(d.Quantize(s, i%nc, bitDepth))
+		}
+	case DataTypeU8, DataTypeI24, DataTypeI24BE:
+		samples := make([]int32, len(src))
+		for i, s := range src {
+			samples[i] = int32(d.Quantize(s, i%nc, bitDepth))
+		}
+		b.setPacked(samples)
+	}
+	b.SourceBitDepth = uint8(bitDepth / 8)
+}
@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDitherer_Quantize(t *testing.T) {
+	tests := []struct {
+		name      string
+		ditherer  *Ditherer
+		maxError  float64
+		wantNoise bool
+	}{
+		{"nil", nil, 0.5, false},
+		{"none", NewDitherer(NoDither, nil, 1), 0.5, false},
+		{"rectangular", NewDitherer(RectangularDither, nil, 1), 1, true},
+		{"triangular", NewDitherer(TriangularDither, nil, 1), 1.5, true},
+		{"high-pass triangular", NewDitherer(HighPassTriangularDither, nil, 1), 1.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var noisy bool
+			for i := 0; i < 1000; i++ {
+				s := 0.3 + float64(i)/100000
+				want := s * 128
+				got := tt.ditherer.Quantize(s, 0, 8)
+				if err := math.Abs(float64(got) - want); err > tt.maxError {
+					t.Fatalf("Quantize(%v) = %d, error of %v LSB", s, got, err)
+				}
+				if got != Float64ToInt(s, 8) {
+					noisy = true
+				}
+			}
+			if noisy != tt.wantNoise {
+				t.Errorf("expected noise to be added: %t", tt.wantNoise)
+			}
+		})
+	}
+}
+
+func TestDitherer_Clipping(t *testing.T) {
+	d := NewDitherer(TriangularDither, NoiseShapingFWeighted, 42)
+	for i := 0; i < 100; i++ {
+		if got := d.Quantize(1, 0, 16); got > 32767 || got < 32700 {
+			t.Fatalf("expected full scale samples to be clipped, got %d", got)
+		}
+		if got := d.Quantize(-1.5, 1, 16); got != -32768 {
+			t.Fatalf("expected full scale samples to be clipped, got %d", got)
+		}
+	}
+}
+
+func TestDitherer_Reproducible(t *testing.T) {
+	buf := &FloatBuffer{Format: FormatStereo44100, Data: make([]float64, 512)}
+	for i := range buf.Data {
+		buf.Data[i] = 0.001 * math.Sin(float64(i)/10)
+	}
+	a := buf.AsDitheredIntBuffer(16, NewDitherer(TriangularDither, NoiseShapingFWeighted, 7))
+	b := buf.AsDitheredIntBuffer(16, NewDitherer(TriangularDither, NoiseShapingFWeighted, 7))
+	if !reflect.DeepEqual(a.Data, b.Data) {
+		t.Error("expected the same seed to produce the same output")
+	}
+	c := buf.AsDitheredIntBuffer(16, NewDitherer(TriangularDither, NoiseShapingFWeighted, 8))
+	if reflect.DeepEqual(a.Data, c.Data) {
+		t.Error("expected different seeds to produce different outputs")
+	}
+	if a.SourceBitDepth != 16 || a.Format.NumChannels != 2 {
+		t.Errorf("unexpected output buffer %d bits, %+v", a.SourceBitDepth, a.Format)
+	}
+}
+
+func TestDitherer_NoiseShaping(t *testing.T) {
+	// With first-order shaping, the total error is the difference of
+	// successive quantization errors so its running sum stays bounded.
+	d := NewDitherer(NoDither, NoiseShapingFirstOrder, 1)
+	var sum float64
+	for i := 0; i < 10000; i++ {
+		s := 0.2 + 0.1*math.Sin(float64(i)/30)
+		sum += float64(d.Quantize(s, 0, 8)) - s*128
+		if math.Abs(sum) > 1 {
+			t.Fatalf("accumulated error of %v LSB after %d samples", sum, i)
+		}
+	}
+}
+
+func TestDitherer_ChangedNoiseShaping(t *testing.T) {
+	d := NewDitherer(TriangularDither, nil, 1)
+	d.Quantize(0.5, 0, 16)
+	d.NoiseShaping = NoiseShapingSecondOrder
+	if v := d.Quantize(0.5, 0, 16); v < 16383 || v > 16385 {
+		t.Errorf("expected 16384 +/- 1 LSB, got %d", v)
+	}
+
+	// the ditherer keeps its own copy of the filter
+	d = NewDitherer(NoDither, NoiseShapingFirstOrder, 1)
+	d.NoiseShaping[0] = 0.5
+	if NoiseShapingFirstOrder[0] != 1 {
+		t.Errorf("expected the built-in filter to be left untouched, got %v", NoiseShapingFirstOrder)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a negative channel")
+		}
+	}()
+	d.Quantize(0, -1, 16)
+}
+
+func TestPCMBuffer_SwitchPrimaryTypeDithered(t *testing.T) {
+	buf := &PCMBuffer{Format: FormatMono44100, F32: []float32{0.5, -0.5, 0.25}, DataType: DataTypeF32}
+	buf.SwitchPrimaryTypeDithered(DataTypeI16, NewDitherer(TriangularDither, nil, 3))
+	if buf.DataType != DataTypeI16 || buf.SourceBitDepth != 2 || buf.F32 != nil {
+		t.Fatalf("unexpected buffer state %+v", buf)
+	}
+	want := []int16{16384, -16384, 8192}
+	for i, s := range buf.I16 {
+		if d := int(s) - int(want[i]); d < -1 || d > 1 {
+			t.Errorf("expected %d +/- 1 LSB, got %d", want[i], s)
+		}
+	}
+
+	// 24 to 16 bit conversions are dithered too
+	buf = &PCMBuffer{Format: FormatMono44100, I32: []int32{128, 128, 128, 128}, DataType: DataTypeI32, SourceBitDepth: 3}
+	buf.SwitchPrimaryTypeDithered(DataTypeI16, NewDitherer(RectangularDither, nil, 3))
+	if buf.DataType != DataTypeI16 || len(buf.I16) != 4 {
+		t.Fatalf("unexpected buffer state %+v", buf)
+	}
+	for _, s := range buf.I16 {
+		if s != 0 && s != 1 {
+			t.Errorf("expected 0.5 LSB to be dithered to 0 or 1, got %d", s)
+		}
+	}
+
+	// widening conversions aren't dithered
+	buf = &PCMBuffer{Format: FormatMono44100, I8: []int8{1, -1}, DataType: DataTypeI8, SourceBitDepth: 1}
+	buf.SwitchPrimaryTypeDithered(DataTypeI16, NewDitherer(TriangularDither, nil, 3))
+	if !reflect.DeepEqual(buf.I16, []int16{256, -256}) {
+		t.Errorf("unexpected widened samples %v", buf.I16)
+	}
+}
@@ -6,5 +6,21 @@ At the heart of the package is the Buffer interface and its implementations:
 FloatBuffer and IntBuffer.
 Decoders, encoders, processors, analyzers and transformers can be written to
 accept or return these types and share a common interface.
+
+Integer samples encoded on N bits are converted to floats in the [-1, 1)
+range by dividing them by 2^(N-1). Going back, float samples are multiplied
+by 2^(N-1), rounded and clipped to the range of a signed N-bit integer.
+The bit depth used is the buffer's SourceBitDepth or, if not set, the bit
+depth of the Format's Sample encoding. Bit depths are never guessed from the
+sample values: IntBuffers default to DefaultBitDepth and PCMBuffers to the
+width of their store, DetectBitDepth offering an explicit estimate.
+See IntToFloat64 and Float64ToInt.
+
+Streams of audio are described by the Reader and Writer interfaces which,
+like their io counterparts, move frames in and out of caller provided
+PCMBuffers and can be chained using Copy.
+
+Raw byte streams are converted to and from PCMBuffers by DecodePCM and
+EncodePCM, the layout of the samples being described by a SampleEncoding.
 */
 package audio
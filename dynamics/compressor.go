@@ -0,0 +1,113 @@
+package dynamics
+
+import (
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// Compressor is a feed-forward downward compressor: it reduces the gain of
+// the signals above its threshold by its ratio.
+type Compressor struct {
+	// Threshold is the level above which the gain is reduced, in dBFS.
+	Threshold float64
+	// Ratio is the number of decibels the level has to rise above the
+	// threshold for the output to rise by one decibel.
+	Ratio float64
+	// Knee is the width in decibels of the soft knee centered on the
+	// threshold, 0 for a hard knee.
+	Knee float64
+	// MakeupGain is the gain applied after compression, in decibels.
+	MakeupGain float64
+	// Attack and Release are the time constants of the gain reduction
+	// going up and down. Hold delays the release after the level falls.
+	Attack, Hold, Release time.Duration
+	// Detection is the way the level is measured, RMSWindow the time
+	// constant of the RMS detection.
+	Detection Detection
+	RMSWindow time.Duration
+	// Link applies the same gain to every channel, computed from the
+	// loudest one.
+	Link bool
+
+	e *engine
+}
+
+// NewCompressor returns a compressor for a stream of the given format, with
+// a -20 dBFS threshold, a 4:1 ratio, a 6 dB knee, 10 ms of attack, 100 ms of
+// release, peak detection and linked channels. It returns an error if the
+// format isn't valid, see audio.Format.Validate.
+func NewCompressor(format *audio.Format) (*Compressor, error) {
+	e, err := newEngine(format)
+	if err != nil {
+		return nil, err
+	}
+	return &Compressor{
+		Threshold: -20,
+		Ratio:     4,
+		Knee:      6,
+		Attack:    10 * time.Millisecond,
+		Release:   100 * time.Millisecond,
+		RMSWindow: 10 * time.Millisecond,
+		Link:      true,
+		e:         e,
+	}, nil
+}
+
+// Curve returns the static gain change of the compressor, in decibels, for
+// a level in dBFS, ignoring the makeup gain.
+func (c *Compressor) Curve(level float64) float64 {
+	if c.Ratio <= 1 {
+		return 0
+	}
+	slope := 1/c.Ratio - 1
+	over := level - c.Threshold
+	switch {
+	case 2*over <= -c.Knee:
+		return 0
+	case 2*over >= c.Knee:
+		return slope * over
+	}
+	over += c.Knee / 2
+	return slope * over * over / (2 * c.Knee)
+}
+
+// Process compresses the frames of buf in place, see audio.TransformFloat.
+// It returns an error wrapping audio.ErrFormatMismatch if buf doesn't have
+// the number of channels and the sample rate of the compressor.
+func (c *Compressor) Process(buf audio.Buffer) error {
+	return c.e.process(buf, nil, c.ballistics())
+}
+
+// ProcessSidechain compresses the frames of buf in place according to the
+// level of sidechain, for instance ducking music under a voice. sidechain
+// must have one channel or the channels of buf, and at least as many
+// frames; an error wrapping audio.ErrFormatMismatch is returned otherwise.
+func (c *Compressor) ProcessSidechain(buf, sidechain audio.Buffer) error {
+	return c.e.process(buf, sidechain, c.ballistics())
+}
+
+func (c *Compressor) ballistics() ballistics {
+	return ballistics{
+		detection: c.Detection,
+		rmsWindow: c.RMSWindow,
+		attack:    c.Attack,
+		hold:      c.Hold,
+		release:   c.Release,
+		link:      c.Link,
+		curve:     c.Curve,
+		makeup:    c.MakeupGain,
+	}
+}
+
+// GainReduction returns the current gain reduction of each channel, in
+// decibels.
+func (c *Compressor) GainReduction() []float64 { return c.e.gainReduction() }
+
+// MaxGainReduction returns the highest gain reduction of each channel since
+// the previous call, in decibels, and starts a new measure.
+func (c *Compressor) MaxGainReduction() []float64 { return c.e.takeMaxGainReduction() }
+
+// Reset clears the state of the compressor, as if it never processed
+// samples.
+func (c *Compressor) Reset() { c.e.reset() }
@@ -0,0 +1,213 @@
+/*
+Package dynamics controls the dynamic range of audio streams with
+compressors, expanders, noise gates and limiters.
+
+Compressor, Expander and Gate are feed-forward processors: they detect the
+level of their input, or of a sidechain buffer, compute the gain change of
+their static curve and smooth it with the attack, hold and release times.
+Linked channels share the same gain, keeping the stereo image steady.
+Limiter delays its input to reduce the gain before the peaks reach the
+output, so they never exceed its ceiling.
+
+The processors keep their state between calls to Process so they can
+process a stream buffer after buffer, and report their gain reduction for
+metering.
+*/
+package dynamics
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// minGainDB is the lowest gain change, in decibels, keeping the smoothing
+// arithmetic finite.
+const minGainDB = -200
+
+// Detection is the way the level of a signal is measured.
+type Detection int
+
+const (
+	// Peak detection follows the absolute sample values, reacting to
+	// transients.
+	Peak Detection = iota
+	// RMS detection follows the root mean square level over a short
+	// window, closer to the perceived loudness.
+	RMS
+)
+
+// String returns the name of the detection.
+func (d Detection) String() string {
+	switch d {
+	case Peak:
+		return "peak"
+	case RMS:
+		return "rms"
+	}
+	return "unknown"
+}
+
+// ballistics holds the settings of an engine for a call to process.
+type ballistics struct {
+	detection                        Detection
+	rmsWindow, attack, hold, release time.Duration
+	link                             bool
+	// opening is set for the processors whose attack raises the gain,
+	// expanders and gates, while the attack of compressors lowers it.
+	opening bool
+	// curve returns the gain change, in decibels, for a level in dBFS.
+	curve  func(level float64) float64
+	makeup float64
+}
+
+// engine detects levels and smooths the gain changes of the feed-forward
+// processors.
+type engine struct {
+	numChannels int
+	sampleRate  int
+	// meanSquare holds the RMS detector state of each channel.
+	meanSquare []float64
+	// gain holds the smoothed gain change of each channel, in decibels.
+	gain []float64
+	// hold holds the number of samples left before each channel releases.
+	hold []int
+	// maxReduction holds the highest gain reduction of each channel since
+	// the last call to MaxGainReduction.
+	maxReduction []float64
+}
+
+func newEngine(format *audio.Format) (*engine, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	nc := format.NumChannels
+	return &engine{
+		numChannels:  nc,
+		sampleRate:   format.SampleRate,
+		meanSquare:   make([]float64, nc),
+		gain:         make([]float64, nc),
+		hold:         make([]int, nc),
+		maxReduction: make([]float64, nc),
+	}, nil
+}
+
+// process applies the gain changes to buf in place, detecting the level of
+// sidechain if not nil and of buf otherwise.
+func (e *engine) process(buf, sidechain audio.Buffer, b ballistics) error {
+	nc := e.numChannels
+	if err := audio.CheckFormat(buf, nc, e.sampleRate); err != nil {
+		return err
+	}
+	var key []float64
+	keyChannels := nc
+	if sidechain != nil && buf != nil {
+		sc := sidechain.AsFloatBuffer()
+		keyChannels = 1
+		if sc.Format != nil && sc.Format.NumChannels > 0 {
+			keyChannels = sc.Format.NumChannels
+		}
+		if keyChannels != 1 && keyChannels != nc {
+			return fmt.Errorf("%w: %d channel sidechain for %d channels", audio.ErrFormatMismatch, keyChannels, nc)
+		}
+		if sc.Format != nil && sc.Format.SampleRate != 0 && sc.Format.SampleRate != e.sampleRate {
+			return fmt.Errorf("%w: %d Hz sidechain for a %d Hz stream", audio.ErrFormatMismatch, sc.Format.SampleRate, e.sampleRate)
+		}
+		if frames := len(sc.Data) / keyChannels; frames < buf.NumFrames() {
+			return fmt.Errorf("%w: %d frames of sidechain for %d frames", audio.ErrFormatMismatch, frames, buf.NumFrames())
+		}
+		key = sc.Data
+	}
+
+	rate := float64(e.sampleRate)
+	rmsCoef := coefficient(b.rmsWindow, rate)
+	attack, release := coefficient(b.attack, rate), coefficient(b.release, rate)
+	holdFrames := int(b.hold.Seconds() * rate)
+	levels := make([]float64, nc)
+	return audio.TransformFloat(buf, func(samples []float64, frame int) {
+		for i := 0; i+nc <= len(samples); i += nc {
+			f := frame + i/nc
+			for c := 0; c < nc; c++ {
+				var x float64
+				switch {
+				case key == nil:
+					x = samples[i+c]
+				case keyChannels == 1:
+					x = key[f]
+				default:
+					x = key[f*nc+c]
+				}
+				if b.detection == RMS {
+					e.meanSquare[c] = x*x + rmsCoef*(e.meanSquare[c]-x*x)
+					levels[c] = math.Sqrt(e.meanSquare[c])
+				} else {
+					levels[c] = math.Abs(x)
+				}
+			}
+			if b.link {
+				peak := levels[0]
+				for _, l := range levels[1:] {
+					peak = max(peak, l)
+				}
+				for c := range levels {
+					levels[c] = peak
+				}
+			}
+			for c := 0; c < nc; c++ {
+				target := max(minGainDB, b.curve(audio.GainToDB(levels[c])))
+				g := e.gain[c]
+				releasing := target > g
+				if b.opening {
+					releasing = target < g
+				}
+				switch {
+				case !releasing:
+					g = target + attack*(g-target)
+					e.hold[c] = holdFrames
+				case e.hold[c] > 0:
+					e.hold[c]--
+				default:
+					g = target + release*(g-target)
+				}
+				e.gain[c] = g
+				e.maxReduction[c] = max(e.maxReduction[c], -g)
+				samples[i+c] *= audio.DBToGain(g + b.makeup)
+			}
+		}
+	})
+}
+
+// gainReduction returns the current gain reduction of each channel.
+func (e *engine) gainReduction() []float64 {
+	out := make([]float64, e.numChannels)
+	for c, g := range e.gain {
+		out[c] = max(0, -g)
+	}
+	return out
+}
+
+// takeMaxGainReduction returns the highest gain reduction of each channel
+// since its last call and starts a new measure.
+func (e *engine) takeMaxGainReduction() []float64 {
+	out := append([]float64(nil), e.maxReduction...)
+	clear(e.maxReduction)
+	return out
+}
+
+func (e *engine) reset() {
+	clear(e.meanSquare)
+	clear(e.gain)
+	clear(e.hold)
+	clear(e.maxReduction)
+}
+
+// coefficient returns the coefficient of a one-pole smoother with the time
+// constant d, 0 for an immediate response.
+func coefficient(d time.Duration, rate float64) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (d.Seconds() * rate))
+}
@@ -0,0 +1,231 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+const rate = 48000
+
+var stereo = &audio.Format{NumChannels: 2, SampleRate: rate}
+
+// sine returns frames stereo frames of a 1 kHz sine of the given peak
+// amplitudes on the left and right channels.
+func sine(frames int, left, right float64) *audio.FloatBuffer {
+	buf := &audio.FloatBuffer{Format: stereo, Data: make([]float64, 2*frames)}
+	for i := 0; i < frames; i++ {
+		s := math.Sin(2 * math.Pi * 1000 * float64(i) / rate)
+		buf.Data[2*i], buf.Data[2*i+1] = left*s, right*s
+	}
+	return buf
+}
+
+// peaks returns the peak levels of the channels of the frames of buf from
+// start on.
+func peaks(buf *audio.FloatBuffer, start int) [2]float64 {
+	var p [2]float64
+	for i := 2 * start; i < len(buf.Data); i++ {
+		p[i%2] = max(p[i%2], math.Abs(buf.Data[i]))
+	}
+	return p
+}
+
+func TestCompressor_Curve(t *testing.T) {
+	c, _ := NewCompressor(stereo)
+	tests := []struct {
+		knee, level, want float64
+	}{
+		{0, -30, 0},
+		{0, -20, 0},
+		{0, -10, -7.5},
+		{6, -23, 0},
+		{6, -20, -0.5625},
+		{6, -17, -2.25},
+		{6, 0, -15},
+	}
+	for _, tt := range tests {
+		c.Knee = tt.knee
+		if got := c.Curve(tt.level); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("knee %v, level %v: expected %v, got %v", tt.knee, tt.level, tt.want, got)
+		}
+	}
+}
+
+func TestCompressor_Process(t *testing.T) {
+	c, err := NewCompressor(stereo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Attack, c.MakeupGain = 0, 3
+	buf := sine(rate, 1, 0.5)
+	// processing in chunks gives the same result as a single call
+	want := sine(rate, 1, 0.5)
+	if err := c.Process(want); err != nil {
+		t.Fatal(err)
+	}
+	c.Reset()
+	for _, chunk := range buf.Split(1000) {
+		if err := c.Process(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range want.Data {
+		if buf.Data[i] != want.Data[i] {
+			t.Fatalf("sample %d: expected %v, got %v", i, want.Data[i], buf.Data[i])
+		}
+	}
+	// the linked channels are reduced by the 15 dB of the left one, the
+	// release ripple aside
+	p := peaks(buf, rate/2)
+	for ch, level := range []float64{1, 0.5} {
+		want := level * audio.DBToGain(-15+3)
+		if math.Abs(p[ch]-want) > 0.02*want {
+			t.Errorf("channel %d: expected a peak of %v, got %v", ch, want, p[ch])
+		}
+	}
+	if got := c.MaxGainReduction(); got[0] < 15 || got[1] < 15 {
+		t.Errorf("expected a gain reduction of at least 15 dB, got %v", got)
+	}
+	if got := c.GainReduction(); got[0] < 14 || got[0] > 15.1 {
+		t.Errorf("expected a gain reduction of about 15 dB, got %v", got)
+	}
+
+	// unlinked, the right channel stays below the threshold
+	c.Reset()
+	c.Link = false
+	c.MakeupGain = 0
+	buf = sine(rate, 1, 0.05)
+	if err := c.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+	if p := peaks(buf, rate/2); math.Abs(p[1]-0.05) > 1e-3 {
+		t.Errorf("expected the right channel to be left untouched, got a peak of %v", p[1])
+	}
+
+	if err := c.Process(&audio.FloatBuffer{Format: audio.FormatMono44100}); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+}
+
+func TestCompressor_ProcessSidechain(t *testing.T) {
+	c, _ := NewCompressor(stereo)
+	c.Threshold, c.Ratio, c.Knee = -40, 10, 0
+	c.Detection = RMS
+	voice := &audio.FloatBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: rate}, Data: make([]float64, rate)}
+	for i := rate / 2; i < rate; i++ {
+		voice.Data[i] = math.Sin(2 * math.Pi * 200 * float64(i) / rate)
+	}
+	music := sine(rate, 0.5, 0.5)
+	if err := c.ProcessSidechain(music, voice); err != nil {
+		t.Fatal(err)
+	}
+	// no ducking without the voice, ducked by the 33 dB the RMS level of
+	// the voice, -3 dBFS, exceeds the threshold by, divided by 10/9
+	if p := peaks(&audio.FloatBuffer{Format: stereo, Data: music.Data[:rate/2]}, 0); math.Abs(p[0]-0.5) > 1e-3 {
+		t.Errorf("expected no ducking before the voice, got a peak of %v", p[0])
+	}
+	want := 0.5 * audio.DBToGain(-(40-3)*0.9)
+	if p := peaks(music, rate*3/4); math.Abs(p[0]-want) > 0.05*want {
+		t.Errorf("expected a peak of %v under the voice, got %v", want, p[0])
+	}
+
+	short := &audio.FloatBuffer{Format: voice.Format, Data: make([]float64, 10)}
+	if err := c.ProcessSidechain(music, short); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch for a short sidechain, got %v", err)
+	}
+	threeChannels := &audio.FloatBuffer{Format: &audio.Format{NumChannels: 3, SampleRate: rate}, Data: make([]float64, 3*rate)}
+	if err := c.ProcessSidechain(music, threeChannels); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch for a 3 channel sidechain, got %v", err)
+	}
+}
+
+func TestExpander_Curve(t *testing.T) {
+	x, _ := NewExpander(stereo)
+	tests := []struct {
+		knee, rng, level, want float64
+	}{
+		{0, 0, -40, 0},
+		{0, 0, -60, -10},
+		{0, 0, -200, -150},
+		{0, 40, -200, -40},
+		{6, 40, -47, 0},
+		{6, 40, -50, -0.75},
+		{6, 40, -53, -3},
+	}
+	for _, tt := range tests {
+		x.Knee, x.Range = tt.knee, tt.rng
+		if got := x.Curve(tt.level); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("knee %v, range %v, level %v: expected %v, got %v", tt.knee, tt.rng, tt.level, tt.want, got)
+		}
+	}
+}
+
+func TestGate(t *testing.T) {
+	g, err := NewGate(stereo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Release = 5 * time.Millisecond
+	// a burst followed by low level noise
+	buf := sine(rate, 0.5, 0.5)
+	for i := rate / 2; i < rate; i++ {
+		buf.Data[2*i] *= 0.0001
+		buf.Data[2*i+1] *= 0.0001
+	}
+	if err := g.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+	if p := peaks(&audio.FloatBuffer{Format: stereo, Data: buf.Data[:rate/2]}, 0); math.Abs(p[0]-0.5) > 1e-9 {
+		t.Errorf("expected the burst to pass, got a peak of %v", p[0])
+	}
+	// the gate stays open during the 50 ms of hold
+	holdEnd := rate/2 + rate/20
+	if p := peaks(&audio.FloatBuffer{Format: stereo, Data: buf.Data[rate : 2*holdEnd-2]}, 0); p[0] < 0.5*0.0001*0.99 {
+		t.Errorf("expected the gate to hold, got a peak of %v", p[0])
+	}
+	if p := peaks(buf, rate*3/4); p[0] > 0.5*0.0001*audio.DBToGain(-79) {
+		t.Errorf("expected the gate to close, got a peak of %v", p[0])
+	}
+	if got := g.GainReduction(); math.Abs(got[0]-80) > 1e-6 {
+		t.Errorf("expected an attenuation of 80 dB, got %v", got)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l, err := NewLimiter(stereo, 2*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Latency() != 96 {
+		t.Fatalf("expected a latency of 96 frames, got %d", l.Latency())
+	}
+	l.Link = false
+	in := sine(rate, 0.5, 0.5)
+	// peaks of varying heights on the left channel
+	for i := 0; i < rate; i += 997 {
+		in.Data[2*i] = float64(i%7) - 3
+	}
+	buf := &audio.FloatBuffer{Format: stereo, Data: append([]float64(nil), in.Data...)}
+	for _, chunk := range buf.Split(333) {
+		if err := l.Process(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ceiling := audio.DBToGain(-1)
+	if p := peaks(buf, 0); p[0] > ceiling {
+		t.Errorf("expected the peaks not to exceed %v, got %v", ceiling, p[0])
+	}
+	// the right channel, below the ceiling, is only delayed
+	for i := 96; i < rate; i++ {
+		if buf.Data[2*i+1] != in.Data[2*(i-96)+1] {
+			t.Fatalf("frame %d: expected %v on the right channel, got %v", i, in.Data[2*(i-96)+1], buf.Data[2*i+1])
+		}
+	}
+	if got := l.MaxGainReduction(); got[0] < 9 || got[1] != 0 {
+		t.Errorf("expected about 10.5 dB of gain reduction on the left channel only, got %v", got)
+	}
+}
@@ -0,0 +1,212 @@
+package dynamics
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// Expander is a feed-forward downward expander: it reduces the gain of the
+// signals below its threshold, the output falling by ratio decibels for
+// each decibel the level falls.
+type Expander struct {
+	// Threshold is the level below which the gain is reduced, in dBFS.
+	Threshold float64
+	// Ratio is the number of decibels the output falls for each decibel
+	// the level falls below the threshold.
+	Ratio float64
+	// Knee is the width in decibels of the soft knee centered on the
+	// threshold, 0 for a hard knee.
+	Knee float64
+	// Range is the maximum gain reduction in decibels, 0 for no limit.
+	Range float64
+	// Attack and Release are the time constants of the gain going up and
+	// down. Hold delays the release after the level falls.
+	Attack, Hold, Release time.Duration
+	// Detection is the way the level is measured, RMSWindow the time
+	// constant of the RMS detection.
+	Detection Detection
+	RMSWindow time.Duration
+	// Link applies the same gain to every channel, computed from the
+	// loudest one.
+	Link bool
+
+	e *engine
+}
+
+// NewExpander returns an expander for a stream of the given format, with a
+// -50 dBFS threshold, a 1:2 ratio, a 6 dB knee, a 40 dB range, 1 ms of
+// attack, 10 ms of hold, 100 ms of release, peak detection and linked
+// channels. It returns an error if the format isn't valid, see
+// audio.Format.Validate.
+func NewExpander(format *audio.Format) (*Expander, error) {
+	e, err := newEngine(format)
+	if err != nil {
+		return nil, err
+	}
+	return &Expander{
+		Threshold: -50,
+		Ratio:     2,
+		Knee:      6,
+		Range:     40,
+		Attack:    time.Millisecond,
+		Hold:      10 * time.Millisecond,
+		Release:   100 * time.Millisecond,
+		RMSWindow: 10 * time.Millisecond,
+		Link:      true,
+		e:         e,
+	}, nil
+}
+
+// Curve returns the static gain change of the expander, in decibels, for a
+// level in dBFS.
+func (x *Expander) Curve(level float64) float64 {
+	if x.Ratio <= 1 {
+		return 0
+	}
+	slope := x.Ratio - 1
+	under := level - x.Threshold
+	var g float64
+	switch {
+	case 2*under >= x.Knee:
+		return 0
+	case 2*under <= -x.Knee:
+		g = slope * under
+	default:
+		under -= x.Knee / 2
+		g = -slope * under * under / (2 * x.Knee)
+	}
+	if x.Range > 0 {
+		g = max(g, -x.Range)
+	}
+	return g
+}
+
+// Process expands the frames of buf in place, see audio.TransformFloat. It
+// returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the sample rate of the expander.
+func (x *Expander) Process(buf audio.Buffer) error {
+	return x.e.process(buf, nil, x.ballistics())
+}
+
+// ProcessSidechain expands the frames of buf in place according to the
+// level of sidechain, see Compressor.ProcessSidechain.
+func (x *Expander) ProcessSidechain(buf, sidechain audio.Buffer) error {
+	return x.e.process(buf, sidechain, x.ballistics())
+}
+
+func (x *Expander) ballistics() ballistics {
+	return ballistics{
+		detection: x.Detection,
+		rmsWindow: x.RMSWindow,
+		attack:    x.Attack,
+		hold:      x.Hold,
+		release:   x.Release,
+		link:      x.Link,
+		opening:   true,
+		curve:     x.Curve,
+	}
+}
+
+// GainReduction returns the current gain reduction of each channel, in
+// decibels.
+func (x *Expander) GainReduction() []float64 { return x.e.gainReduction() }
+
+// MaxGainReduction returns the highest gain reduction of each channel since
+// the previous call, in decibels, and starts a new measure.
+func (x *Expander) MaxGainReduction() []float64 { return x.e.takeMaxGainReduction() }
+
+// Reset clears the state of the expander, as if it never processed samples.
+func (x *Expander) Reset() { x.e.reset() }
+
+// Gate is a noise gate: it attenuates the signals below its threshold by
+// its range, opening during the attack and closing during the release once
+// the hold time elapsed.
+type Gate struct {
+	// Threshold is the level below which the gate closes, in dBFS.
+	Threshold float64
+	// Range is the attenuation of the closed gate in decibels, +Inf to
+	// mute it.
+	Range float64
+	// Attack and Release are the opening and closing times of the gate.
+	// Hold keeps the gate open after the level falls.
+	Attack, Hold, Release time.Duration
+	// Detection is the way the level is measured, RMSWindow the time
+	// constant of the RMS detection.
+	Detection Detection
+	RMSWindow time.Duration
+	// Link opens and closes every channel together, according to the
+	// loudest one.
+	Link bool
+
+	e *engine
+}
+
+// NewGate returns a gate for a stream of the given format, with a -60 dBFS
+// threshold, an 80 dB range, 0.5 ms of attack, 50 ms of hold, 100 ms of
+// release, peak detection and linked channels. It returns an error if the
+// format isn't valid, see audio.Format.Validate.
+func NewGate(format *audio.Format) (*Gate, error) {
+	e, err := newEngine(format)
+	if err != nil {
+		return nil, err
+	}
+	return &Gate{
+		Threshold: -60,
+		Range:     80,
+		Attack:    500 * time.Microsecond,
+		Hold:      50 * time.Millisecond,
+		Release:   100 * time.Millisecond,
+		RMSWindow: 10 * time.Millisecond,
+		Link:      true,
+		e:         e,
+	}, nil
+}
+
+// Curve returns the static gain change of the gate, in decibels, for a
+// level in dBFS.
+func (g *Gate) Curve(level float64) float64 {
+	if level >= g.Threshold {
+		return 0
+	}
+	return -math.Abs(g.Range)
+}
+
+// Process gates the frames of buf in place, see audio.TransformFloat. It
+// returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the sample rate of the gate.
+func (g *Gate) Process(buf audio.Buffer) error {
+	return g.e.process(buf, nil, g.ballistics())
+}
+
+// ProcessSidechain gates the frames of buf in place according to the level
+// of sidechain, see Compressor.ProcessSidechain.
+func (g *Gate) ProcessSidechain(buf, sidechain audio.Buffer) error {
+	return g.e.process(buf, sidechain, g.ballistics())
+}
+
+func (g *Gate) ballistics() ballistics {
+	return ballistics{
+		detection: g.Detection,
+		rmsWindow: g.RMSWindow,
+		attack:    g.Attack,
+		hold:      g.Hold,
+		release:   g.Release,
+		link:      g.Link,
+		opening:   true,
+		curve:     g.Curve,
+	}
+}
+
+// GainReduction returns the current attenuation of each channel, in
+// decibels.
+func (g *Gate) GainReduction() []float64 { return g.e.gainReduction() }
+
+// MaxGainReduction returns the highest attenuation of each channel since the
+// previous call, in decibels, and starts a new measure.
+func (g *Gate) MaxGainReduction() []float64 { return g.e.takeMaxGainReduction() }
+
+// Reset clears the state of the gate, as if it never processed samples. The
+// gate starts open.
+func (g *Gate) Reset() { g.e.reset() }
@@ -0,0 +1,235 @@
+package dynamics
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// Limiter is a brickwall look-ahead limiter: it delays its input by the
+// look-ahead time so the gain is already reduced when a peak reaches the
+// output, which never exceeds the ceiling. The gain reduction ramps down
+// over the look-ahead time and recovers with the release time.
+type Limiter struct {
+	// Ceiling is the highest absolute output level, in dBFS.
+	Ceiling float64
+	// Release is the time constant of the gain recovery.
+	Release time.Duration
+	// Link applies the same gain to every channel, computed from the
+	// loudest one.
+	Link bool
+
+	numChannels int
+	sampleRate  int
+	lookahead   int
+	// delay holds the last lookahead input samples of each channel, read
+	// and written at pos.
+	delay [][]float64
+	pos   int
+	// chains hold the gain computation state of each channel, only the
+	// first one being used when the channels are linked.
+	chains       []*limiterChain
+	maxReduction []float64
+}
+
+// limiterChain computes the gain of a channel of a limiter.
+type limiterChain struct {
+	// min holds the lowest required gain over the look-ahead window.
+	min minWindow
+	// gain is the released gain.
+	gain float64
+	// ramp holds the released gains of the look-ahead window, summed in
+	// sum to smooth the gain changes.
+	ramp []float64
+	pos  int
+	sum  float64
+}
+
+// NewLimiter returns a limiter for a stream of the given format looking
+// ahead by lookahead, with a -1 dBFS ceiling, 50 ms of release and linked
+// channels. The output is delayed by the look-ahead time, see Latency. It
+// returns an error if the format isn't valid, see audio.Format.Validate.
+func NewLimiter(format *audio.Format, lookahead time.Duration) (*Limiter, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	nc := format.NumChannels
+	l := &Limiter{
+		Ceiling:      -1,
+		Release:      50 * time.Millisecond,
+		Link:         true,
+		numChannels:  nc,
+		sampleRate:   format.SampleRate,
+		lookahead:    max(0, int(math.Round(lookahead.Seconds()*float64(format.SampleRate)))),
+		delay:        make([][]float64, nc),
+		chains:       make([]*limiterChain, nc),
+		maxReduction: make([]float64, nc),
+	}
+	for c := 0; c < nc; c++ {
+		l.delay[c] = make([]float64, l.lookahead)
+		l.chains[c] = newLimiterChain(l.lookahead + 1)
+	}
+	return l, nil
+}
+
+func newLimiterChain(n int) *limiterChain {
+	ch := &limiterChain{
+		min:  newMinWindow(n),
+		gain: 1,
+		ramp: make([]float64, n),
+		sum:  float64(n),
+	}
+	for i := range ch.ramp {
+		ch.ramp[i] = 1
+	}
+	return ch
+}
+
+// Latency returns the delay of the output, in frames. Process that many
+// silent frames to flush the limiter.
+func (l *Limiter) Latency() int { return l.lookahead }
+
+// Process limits the frames of buf in place, see audio.TransformFloat. It
+// returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the sample rate of the limiter.
+func (l *Limiter) Process(buf audio.Buffer) error {
+	nc := l.numChannels
+	if err := audio.CheckFormat(buf, nc, l.sampleRate); err != nil {
+		return err
+	}
+	ceiling := audio.DBToGain(l.Ceiling)
+	release := coefficient(l.Release, float64(l.sampleRate))
+	gains := make([]float64, nc)
+	return audio.TransformFloat(buf, func(samples []float64, _ int) {
+		for i := 0; i+nc <= len(samples); i += nc {
+			frame := samples[i : i+nc]
+			if l.Link {
+				var peak float64
+				for _, x := range frame {
+					peak = max(peak, math.Abs(x))
+				}
+				g := l.chains[0].next(required(peak, ceiling), release)
+				for c := range gains {
+					gains[c] = g
+				}
+			} else {
+				for c, x := range frame {
+					gains[c] = l.chains[c].next(required(math.Abs(x), ceiling), release)
+				}
+			}
+			for c, x := range frame {
+				if l.lookahead > 0 {
+					x, l.delay[c][l.pos] = l.delay[c][l.pos], x
+				}
+				// guard against the rounding errors of the smoothing
+				y := x * gains[c]
+				y = max(-ceiling, min(ceiling, y))
+				frame[c] = y
+				l.maxReduction[c] = max(l.maxReduction[c], -audio.GainToDB(gains[c]))
+			}
+			if l.lookahead > 0 {
+				l.pos = (l.pos + 1) % l.lookahead
+			}
+		}
+	})
+}
+
+// required returns the gain bringing a peak down to the ceiling.
+func required(peak, ceiling float64) float64 {
+	if peak <= ceiling {
+		return 1
+	}
+	return ceiling / peak
+}
+
+// next returns the gain to apply to the sample entering the delay line
+// len(ramp)-1 frames ago, given the gain required by the sample entering it.
+// The moving average of the released minimum over the window never exceeds
+// the gain required by the oldest sample of the window.
+func (ch *limiterChain) next(required, release float64) float64 {
+	h := ch.min.push(required)
+	if h < ch.gain {
+		ch.gain = h
+	} else {
+		ch.gain = h + release*(ch.gain-h)
+	}
+	ch.sum += ch.gain - ch.ramp[ch.pos]
+	ch.ramp[ch.pos] = ch.gain
+	ch.pos++
+	if ch.pos == len(ch.ramp) {
+		// recompute the sum to discard the accumulated rounding errors
+		ch.pos, ch.sum = 0, 0
+		for _, g := range ch.ramp {
+			ch.sum += g
+		}
+	}
+	return min(1, ch.sum/float64(len(ch.ramp)))
+}
+
+// GainReduction returns the current gain reduction of each channel, in
+// decibels.
+func (l *Limiter) GainReduction() []float64 {
+	out := make([]float64, l.numChannels)
+	for c := range out {
+		ch := l.chains[c]
+		if l.Link {
+			ch = l.chains[0]
+		}
+		out[c] = max(0, -audio.GainToDB(ch.sum/float64(len(ch.ramp))))
+	}
+	return out
+}
+
+// MaxGainReduction returns the highest gain reduction of each channel since
+// the previous call, in decibels, and starts a new measure.
+func (l *Limiter) MaxGainReduction() []float64 {
+	out := append([]float64(nil), l.maxReduction...)
+	clear(l.maxReduction)
+	return out
+}
+
+// Reset clears the state of the limiter, as if it never processed samples.
+func (l *Limiter) Reset() {
+	for c := range l.chains {
+		clear(l.delay[c])
+		l.chains[c] = newLimiterChain(l.lookahead + 1)
+	}
+	l.pos = 0
+	clear(l.maxReduction)
+}
+
+// minWindow computes the minimum of the last n values pushed, using a
+// monotonic queue.
+type minWindow struct {
+	n int
+	t int
+	// vals and times hold the candidate minimums and the times they were
+	// pushed at, in a ring of n entries starting at head.
+	vals  []float64
+	times []int
+	head  int
+	len   int
+}
+
+func newMinWindow(n int) minWindow {
+	return minWindow{n: n, vals: make([]float64, n), times: make([]int, n)}
+}
+
+// push adds v to the window and returns the minimum of the window.
+func (w *minWindow) push(v float64) float64 {
+	// drop the candidates greater than v, they can't be the minimum
+	// anymore, then the one leaving the window
+	for w.len > 0 && w.vals[(w.head+w.len-1)%w.n] >= v {
+		w.len--
+	}
+	if w.len > 0 && w.times[w.head] <= w.t-w.n {
+		w.head = (w.head + 1) % w.n
+		w.len--
+	}
+	i := (w.head + w.len) % w.n
+	w.vals[i], w.times[i] = v, w.t
+	w.len++
+	w.t++
+	return w.vals[w.head]
+}
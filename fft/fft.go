@@ -0,0 +1,199 @@
+/*
+Package fft computes discrete Fourier transforms of complex and real signals.
+
+A Plan precomputes the twiddle factors and the bit reversal permutation of a
+transform size so the signals of that size can be transformed repeatedly.
+Power of two sizes use the iterative radix-2 Cooley-Tukey algorithm, other
+sizes Bluestein's algorithm, which expresses the transform as a convolution
+computed with power of two transforms. A RealPlan transforms real signals,
+which only need half of the spectrum.
+*/
+package fft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// ErrInvalidSize is returned when a transform size isn't supported.
+var ErrInvalidSize = errors.New("invalid transform size")
+
+// Plan computes the transforms of signals of a given size. A plan holds
+// scratch space and so isn't safe for concurrent use.
+type Plan struct {
+	n int
+	// twiddles holds exp(-2πik/n) for k in [0, n/2).
+	twiddles []complex128
+	// reversed holds the bit reversed index of each index.
+	reversed []int
+	// bluestein is set when n isn't a power of two.
+	bluestein *bluestein
+}
+
+// bluestein holds the state of Bluestein's algorithm for a size n.
+type bluestein struct {
+	// plan transforms the power of two convolution size.
+	plan *Plan
+	// chirp holds exp(-πik²/n) for k in [0, n).
+	chirp []complex128
+	// kernel holds the transform of the conjugated chirp, wrapped around.
+	kernel []complex128
+	// scratch holds the signal being convolved.
+	scratch []complex128
+}
+
+// NewPlan returns a plan for signals of n samples. It returns an error
+// wrapping ErrInvalidSize if n isn't positive.
+func NewPlan(n int) (*Plan, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSize, n)
+	}
+	if n&(n-1) != 0 {
+		return newBluesteinPlan(n), nil
+	}
+	p := &Plan{
+		n:        n,
+		twiddles: make([]complex128, n/2),
+		reversed: make([]int, n),
+	}
+	for k := range p.twiddles {
+		p.twiddles[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+	}
+	shift := bits.UintSize - bits.Len(uint(n-1))
+	for i := range p.reversed {
+		p.reversed[i] = int(bits.Reverse(uint(i)) >> uint(shift))
+	}
+	return p, nil
+}
+
+func newBluesteinPlan(n int) *Plan {
+	m := NextPowerOfTwo(2*n - 1)
+	sub, _ := NewPlan(m)
+	b := &bluestein{
+		plan:    sub,
+		chirp:   make([]complex128, n),
+		kernel:  make([]complex128, m),
+		scratch: make([]complex128, m),
+	}
+	for k := range b.chirp {
+		// k² modulo 2n keeps the angle small, and so accurate, for large k
+		k2 := (k * k) % (2 * n)
+		b.chirp[k] = cmplx.Rect(1, -math.Pi*float64(k2)/float64(n))
+	}
+	b.kernel[0] = cmplx.Conj(b.chirp[0])
+	for k := 1; k < n; k++ {
+		b.kernel[k] = cmplx.Conj(b.chirp[k])
+		b.kernel[m-k] = b.kernel[k]
+	}
+	sub.Forward(b.kernel)
+	return &Plan{n: n, bluestein: b}
+}
+
+// Len returns the size of the signals transformed by the plan.
+func (p *Plan) Len() int { return p.n }
+
+// Forward replaces x by its discrete Fourier transform. x must hold Len
+// samples.
+func (p *Plan) Forward(x []complex128) {
+	p.transform(x, false)
+}
+
+// Inverse replaces x by its inverse discrete Fourier transform, scaled by
+// 1/Len so Inverse undoes Forward. x must hold Len samples.
+func (p *Plan) Inverse(x []complex128) {
+	p.transform(x, true)
+	scale := complex(1/float64(p.n), 0)
+	for i := range x {
+		x[i] *= scale
+	}
+}
+
+func (p *Plan) transform(x []complex128, inverse bool) {
+	if len(x) != p.n {
+		panic(fmt.Sprintf("fft: transforming %d samples with a plan of %d samples", len(x), p.n))
+	}
+	if p.bluestein != nil {
+		p.bluestein.transform(x, inverse)
+		return
+	}
+	for i, j := range p.reversed {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= p.n; size <<= 1 {
+		half, step := size/2, p.n/size
+		for start := 0; start < p.n; start += size {
+			for k := 0; k < half; k++ {
+				w := p.twiddles[k*step]
+				if inverse {
+					w = cmplx.Conj(w)
+				}
+				a, b := x[start+k], w*x[start+k+half]
+				x[start+k], x[start+k+half] = a+b, a-b
+			}
+		}
+	}
+}
+
+// transform computes the unscaled transform of x as the convolution of the
+// chirped signal with the conjugated chirp. The inverse transform is the
+// conjugate of the forward transform of the conjugated signal.
+func (b *bluestein) transform(x []complex128, inverse bool) {
+	s := b.scratch
+	clear(s)
+	for k, v := range x {
+		if inverse {
+			v = cmplx.Conj(v)
+		}
+		s[k] = v * b.chirp[k]
+	}
+	b.plan.Forward(s)
+	for k := range s {
+		s[k] *= b.kernel[k]
+	}
+	b.plan.Inverse(s)
+	for k := range x {
+		v := s[k] * b.chirp[k]
+		if inverse {
+			v = cmplx.Conj(v)
+		}
+		x[k] = v
+	}
+}
+
+// FFT returns the discrete Fourier transform of x. It returns an error
+// wrapping ErrInvalidSize if x is empty.
+func FFT(x []complex128) ([]complex128, error) {
+	p, err := NewPlan(len(x))
+	if err != nil {
+		return nil, err
+	}
+	out := append([]complex128(nil), x...)
+	p.Forward(out)
+	return out, nil
+}
+
+// IFFT returns the inverse discrete Fourier transform of x, see
+// Plan.Inverse. It returns an error wrapping ErrInvalidSize if x is empty.
+func IFFT(x []complex128) ([]complex128, error) {
+	p, err := NewPlan(len(x))
+	if err != nil {
+		return nil, err
+	}
+	out := append([]complex128(nil), x...)
+	p.Inverse(out)
+	return out, nil
+}
+
+// NextPowerOfTwo returns the smallest power of two greater than or equal to
+// n.
+func NextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
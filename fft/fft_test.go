@@ -0,0 +1,99 @@
+package fft
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// dft returns the discrete Fourier transform of x computed from its
+// definition.
+func dft(x []complex128) []complex128 {
+	n := len(x)
+	out := make([]complex128, n)
+	for k := range out {
+		for t, v := range x {
+			out[k] += v * cmplx.Rect(1, -2*math.Pi*float64(k*t)/float64(n))
+		}
+	}
+	return out
+}
+
+func TestFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 2, 3, 4, 5, 8, 12, 64, 100, 512, 1000} {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rng.Float64()-0.5, rng.Float64()-0.5)
+		}
+		got, err := FFT(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, want := range dft(x) {
+			if cmplx.Abs(got[k]-want) > 1e-9 {
+				t.Fatalf("n=%d bin %d: expected %v, got %v", n, k, want, got[k])
+			}
+		}
+		back, err := IFFT(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range x {
+			if cmplx.Abs(back[i]-x[i]) > 1e-12 {
+				t.Fatalf("n=%d sample %d: expected %v after a round trip, got %v", n, i, x[i], back[i])
+			}
+		}
+	}
+	if _, err := FFT(nil); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("expected ErrInvalidSize, got %v", err)
+	}
+}
+
+func TestRealFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, n := range []int{1, 2, 3, 7, 8, 10, 64, 100, 1000} {
+		x := make([]float64, n)
+		c := make([]complex128, n)
+		for i := range x {
+			x[i] = rng.Float64() - 0.5
+			c[i] = complex(x[i], 0)
+		}
+		got, err := RealFFT(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := dft(c)
+		if len(got) != n/2+1 {
+			t.Fatalf("n=%d: expected %d bins, got %d", n, n/2+1, len(got))
+		}
+		for k := range got {
+			if cmplx.Abs(got[k]-want[k]) > 1e-9 {
+				t.Fatalf("n=%d bin %d: expected %v, got %v", n, k, want[k], got[k])
+			}
+		}
+		back, err := RealIFFT(got, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range x {
+			if math.Abs(back[i]-x[i]) > 1e-12 {
+				t.Fatalf("n=%d sample %d: expected %v after a round trip, got %v", n, i, x[i], back[i])
+			}
+		}
+	}
+	if _, err := RealIFFT(make([]complex128, 3), 8); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("expected ErrInvalidSize, got %v", err)
+	}
+}
+
+func TestNextPowerOfTwo(t *testing.T) {
+	tests := []struct{ n, want int }{{0, 1}, {1, 1}, {2, 2}, {3, 4}, {1000, 1024}, {1024, 1024}}
+	for _, tt := range tests {
+		if got := NextPowerOfTwo(tt.n); got != tt.want {
+			t.Errorf("%d: expected %d, got %d", tt.n, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// RealPlan computes the transforms of real signals of a given size. The
+// spectrum of a real signal is conjugate symmetric, so only its first n/2+1
+// bins, from DC to the Nyquist frequency, are computed. Even sizes are
+// transformed as complex signals of half the size. A plan holds scratch
+// space and so isn't safe for concurrent use.
+type RealPlan struct {
+	n    int
+	plan *Plan
+	// twiddles holds exp(-2πik/n) for k in [0, n/2), for even sizes.
+	twiddles []complex128
+	scratch  []complex128
+}
+
+// NewRealPlan returns a plan for real signals of n samples. It returns an
+// error wrapping ErrInvalidSize if n isn't positive.
+func NewRealPlan(n int) (*RealPlan, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSize, n)
+	}
+	size := n
+	if n%2 == 0 {
+		size = n / 2
+	}
+	plan, err := NewPlan(size)
+	if err != nil {
+		return nil, err
+	}
+	p := &RealPlan{n: n, plan: plan, scratch: make([]complex128, size)}
+	if n%2 == 0 {
+		p.twiddles = make([]complex128, n/2)
+		for k := range p.twiddles {
+			p.twiddles[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+		}
+	}
+	return p, nil
+}
+
+// Len returns the size of the signals transformed by the plan.
+func (p *RealPlan) Len() int { return p.n }
+
+// Bins returns the number of bins of the spectra, n/2+1.
+func (p *RealPlan) Bins() int { return p.n/2 + 1 }
+
+// Forward writes the first Bins bins of the discrete Fourier transform of x
+// to dst. x must hold Len samples and dst Bins bins.
+func (p *RealPlan) Forward(dst []complex128, x []float64) {
+	if len(x) != p.n || len(dst) != p.Bins() {
+		panic(fmt.Sprintf("fft: transforming %d samples to %d bins with a real plan of %d samples", len(x), len(dst), p.n))
+	}
+	z := p.scratch
+	if p.twiddles == nil {
+		for i, v := range x {
+			z[i] = complex(v, 0)
+		}
+		p.plan.Forward(z)
+		copy(dst, z)
+		return
+	}
+	// pack the even samples in the real parts and the odd samples in the
+	// imaginary parts, then separate their spectra
+	half := p.n / 2
+	for i := range z {
+		z[i] = complex(x[2*i], x[2*i+1])
+	}
+	p.plan.Forward(z)
+	for k := 0; k <= half; k++ {
+		a, b := z[k%half], cmplx.Conj(z[(half-k)%half])
+		even := (a + b) / 2
+		odd := (a - b) / 2i
+		w := complex(-1, 0) // exp(-2πik/n) at k = n/2
+		if k < half {
+			w = p.twiddles[k]
+		}
+		dst[k] = even + w*odd
+	}
+}
+
+// Inverse writes the real signal of Len samples whose spectrum starts with
+// the Bins bins of spec to dst, undoing Forward. The imaginary parts of the
+// DC and Nyquist bins are ignored.
+func (p *RealPlan) Inverse(dst []float64, spec []complex128) {
+	if len(dst) != p.n || len(spec) != p.Bins() {
+		panic(fmt.Sprintf("fft: transforming %d bins to %d samples with a real plan of %d samples", len(spec), len(dst), p.n))
+	}
+	z := p.scratch
+	if p.twiddles == nil {
+		z[0] = complex(real(spec[0]), 0)
+		for k := 1; k < len(spec); k++ {
+			z[k] = spec[k]
+			z[p.n-k] = cmplx.Conj(spec[k])
+		}
+		p.plan.Inverse(z)
+		for i := range dst {
+			dst[i] = real(z[i])
+		}
+		return
+	}
+	half := p.n / 2
+	for k := 0; k < half; k++ {
+		a, b := spec[k], cmplx.Conj(spec[half-k])
+		if k == 0 {
+			a, b = complex(real(spec[0]), 0), complex(real(spec[half]), 0)
+		}
+		even := (a + b) / 2
+		odd := (a - b) / 2 * cmplx.Conj(p.twiddles[k])
+		z[k] = even + 1i*odd
+	}
+	p.plan.Inverse(z)
+	for i, v := range z {
+		dst[2*i], dst[2*i+1] = real(v), imag(v)
+	}
+}
+
+// RealFFT returns the first len(x)/2+1 bins of the discrete Fourier
+// transform of the real signal x. It returns an error wrapping
+// ErrInvalidSize if x is empty.
+func RealFFT(x []float64) ([]complex128, error) {
+	p, err := NewRealPlan(len(x))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]complex128, p.Bins())
+	p.Forward(out, x)
+	return out, nil
+}
+
+// RealIFFT returns the real signal of n samples whose spectrum starts with
+// the bins spec, see RealPlan.Inverse. It returns an error wrapping
+// ErrInvalidSize if n isn't positive or spec doesn't hold n/2+1 bins.
+func RealIFFT(spec []complex128, n int) ([]float64, error) {
+	p, err := NewRealPlan(n)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec) != p.Bins() {
+		return nil, fmt.Errorf("%w: %d bins for %d samples", ErrInvalidSize, len(spec), n)
+	}
+	out := make([]float64, n)
+	p.Inverse(out, spec)
+	return out, nil
+}
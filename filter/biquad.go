@@ -0,0 +1,127 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Biquad holds the coefficients of a second order IIR filter section,
+// normalized so a0 is 1:
+//
+//	y[n] = B0*x[n] + B1*x[n-1] + B2*x[n-2] - A1*y[n-1] - A2*y[n-2]
+//
+// First order sections have B2 and A2 set to 0.
+type Biquad struct {
+	B0, B1, B2, A1, A2 float64
+}
+
+// Response returns the complex frequency response of the section at freq Hz
+// for the sample rate rate.
+func (b Biquad) Response(rate, freq float64) complex128 {
+	z1 := cmplx.Exp(complex(0, -2*math.Pi*freq/rate))
+	z2 := z1 * z1
+	num := complex(b.B0, 0) + complex(b.B1, 0)*z1 + complex(b.B2, 0)*z2
+	den := 1 + complex(b.A1, 0)*z1 + complex(b.A2, 0)*z2
+	return num / den
+}
+
+// rbj returns the common terms of the RBJ cookbook formulas: the cosine of
+// the normalized angular frequency and alpha.
+func rbj(rate, freq, q float64) (cosw, alpha float64) {
+	w := 2 * math.Pi * freq / rate
+	return math.Cos(w), math.Sin(w) / (2 * q)
+}
+
+// normalize divides the coefficients by a0.
+func normalize(b0, b1, b2, a0, a1, a2 float64) Biquad {
+	return Biquad{B0: b0 / a0, B1: b1 / a0, B2: b2 / a0, A1: a1 / a0, A2: a2 / a0}
+}
+
+// LowPass returns a low-pass section cutting at freq Hz with the quality
+// factor q (0.7071 for a flat pass band).
+func LowPass(rate, freq, q float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	return normalize((1-cosw)/2, 1-cosw, (1-cosw)/2, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// HighPass returns a high-pass section cutting at freq Hz with the quality
+// factor q.
+func HighPass(rate, freq, q float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	return normalize((1+cosw)/2, -(1 + cosw), (1+cosw)/2, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// BandPass returns a band-pass section centered on freq Hz with a 0 dB peak
+// gain, q setting the bandwidth.
+func BandPass(rate, freq, q float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	return normalize(alpha, 0, -alpha, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// Notch returns a section rejecting freq Hz, q setting the bandwidth.
+func Notch(rate, freq, q float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	return normalize(1, -2*cosw, 1, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// AllPass returns a section shifting the phase around freq Hz without
+// changing the magnitude.
+func AllPass(rate, freq, q float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	return normalize(1-alpha, -2*cosw, 1+alpha, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// Peaking returns a peaking equalizer section boosting or cutting by gainDB
+// decibels around freq Hz, q setting the bandwidth.
+func Peaking(rate, freq, q, gainDB float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	a := math.Pow(10, gainDB/40)
+	return normalize(1+alpha*a, -2*cosw, 1-alpha*a, 1+alpha/a, -2*cosw, 1-alpha/a)
+}
+
+// LowShelf returns a section boosting or cutting by gainDB decibels the
+// frequencies under freq Hz, q setting the slope (0.7071 for the steepest
+// slope without overshoot).
+func LowShelf(rate, freq, q, gainDB float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	a := math.Pow(10, gainDB/40)
+	s := 2 * math.Sqrt(a) * alpha
+	return normalize(
+		a*((a+1)-(a-1)*cosw+s),
+		2*a*((a-1)-(a+1)*cosw),
+		a*((a+1)-(a-1)*cosw-s),
+		(a+1)+(a-1)*cosw+s,
+		-2*((a-1)+(a+1)*cosw),
+		(a+1)+(a-1)*cosw-s,
+	)
+}
+
+// HighShelf returns a section boosting or cutting by gainDB decibels the
+// frequencies above freq Hz, q setting the slope.
+func HighShelf(rate, freq, q, gainDB float64) Biquad {
+	cosw, alpha := rbj(rate, freq, q)
+	a := math.Pow(10, gainDB/40)
+	s := 2 * math.Sqrt(a) * alpha
+	return normalize(
+		a*((a+1)+(a-1)*cosw+s),
+		-2*a*((a-1)+(a+1)*cosw),
+		a*((a+1)+(a-1)*cosw-s),
+		(a+1)-(a-1)*cosw+s,
+		2*((a-1)-(a+1)*cosw),
+		(a+1)-(a-1)*cosw-s,
+	)
+}
+
+// firstOrderLowPass returns a first order low-pass section cutting at freq
+// Hz, designed with the bilinear transform.
+func firstOrderLowPass(rate, freq float64) Biquad {
+	k := math.Tan(math.Pi * freq / rate)
+	return Biquad{B0: k / (1 + k), B1: k / (1 + k), A1: (k - 1) / (1 + k)}
+}
+
+// firstOrderHighPass returns a first order high-pass section cutting at freq
+// Hz, designed with the bilinear transform.
+func firstOrderHighPass(rate, freq float64) Biquad {
+	k := math.Tan(math.Pi * freq / rate)
+	return Biquad{B0: 1 / (1 + k), B1: -1 / (1 + k), A1: (k - 1) / (1 + k)}
+}
@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+)
+
+// ButterworthLowPass returns a low-pass Butterworth filter of the given
+// order (maximally flat pass band, -3 dB at freq Hz). It returns
+// ErrInvalidOrder if the order isn't positive.
+func ButterworthLowPass(rate, freq float64, order int) (Cascade, error) {
+	return butterworth(order, func(q float64) Biquad {
+		return LowPass(rate, freq, q)
+	}, firstOrderLowPass(rate, freq))
+}
+
+// ButterworthHighPass returns a high-pass Butterworth filter of the given
+// order. It returns ErrInvalidOrder if the order isn't positive.
+func ButterworthHighPass(rate, freq float64, order int) (Cascade, error) {
+	return butterworth(order, func(q float64) Biquad {
+		return HighPass(rate, freq, q)
+	}, firstOrderHighPass(rate, freq))
+}
+
+// LinkwitzRileyLowPass returns the low-pass filter of a Linkwitz-Riley
+// crossover of the given even order (-6 dB at freq Hz), made of two cascaded
+// Butterworth filters of half the order. It returns ErrInvalidOrder if the
+// order isn't a positive even number.
+func LinkwitzRileyLowPass(rate, freq float64, order int) (Cascade, error) {
+	return linkwitzRiley(order, func(n int) (Cascade, error) {
+		return ButterworthLowPass(rate, freq, n)
+	})
+}
+
+// LinkwitzRileyHighPass returns the high-pass filter of a Linkwitz-Riley
+// crossover of the given even order. Summing the outputs of the low-pass and
+// high-pass filters gives a flat magnitude response: for orders 2, 6, 10...,
+// whose outputs would otherwise be in antiphase at the crossover, the
+// polarity of the high-pass filter is inverted. It returns ErrInvalidOrder if
+// the order isn't a positive even number.
+func LinkwitzRileyHighPass(rate, freq float64, order int) (Cascade, error) {
+	c, err := linkwitzRiley(order, func(n int) (Cascade, error) {
+		return ButterworthHighPass(rate, freq, n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if order%4 == 2 {
+		c[0].B0, c[0].B1, c[0].B2 = -c[0].B0, -c[0].B1, -c[0].B2
+	}
+	return c, nil
+}
+
+// butterworth returns the sections of a Butterworth filter of the given
+// order, section returning a second order section of quality factor q and
+// first the first order section used by odd orders.
+func butterworth(order int, section func(q float64) Biquad, first Biquad) (Cascade, error) {
+	if order < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidOrder, order)
+	}
+	var c Cascade
+	for k := 0; k < order/2; k++ {
+		q := 1 / (2 * math.Sin(float64(2*k+1)*math.Pi/float64(2*order)))
+		c = append(c, section(q))
+	}
+	if order%2 == 1 {
+		c = append(c, first)
+	}
+	return c, nil
+}
+
+// linkwitzRiley returns design(order/2) cascaded twice.
+func linkwitzRiley(order int, design func(n int) (Cascade, error)) (Cascade, error) {
+	if order < 2 || order%2 != 0 {
+		return nil, fmt.Errorf("%w: %d isn't a positive even order", ErrInvalidOrder, order)
+	}
+	c, err := design(order / 2)
+	if err != nil {
+		return nil, err
+	}
+	return append(c, c...), nil
+}
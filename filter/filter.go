@@ -0,0 +1,100 @@
+/*
+Package filter applies IIR filters to audio buffers.
+
+Filters are described by cascades of second order sections (biquads), built
+from the RBJ audio EQ cookbook formulas or from Butterworth and
+Linkwitz-Riley designs of any order. A Filter runs a cascade over the
+interleaved channels of buffers, in place, keeping the state of each channel
+between calls so a stream can be filtered buffer after buffer.
+*/
+package filter
+
+import (
+	"errors"
+	"math/cmplx"
+
+	"github.com/go-audio/audio"
+)
+
+// ErrInvalidOrder is returned when a filter order isn't supported by a
+// design.
+var ErrInvalidOrder = errors.New("invalid filter order")
+
+// Cascade is a series of sections applied one after the other.
+type Cascade []Biquad
+
+// Response returns the complex frequency response of the cascade at freq Hz
+// for the sample rate rate.
+func (c Cascade) Response(rate, freq float64) complex128 {
+	r := complex(1, 0)
+	for _, b := range c {
+		r *= b.Response(rate, freq)
+	}
+	return r
+}
+
+// MagnitudeDB returns the gain of the cascade at freq Hz, in decibels.
+func (c Cascade) MagnitudeDB(rate, freq float64) float64 {
+	return audio.GainToDB(cmplx.Abs(c.Response(rate, freq)))
+}
+
+// Filter applies a cascade to the channels of a stream, using the transposed
+// direct form II.
+type Filter struct {
+	sections    Cascade
+	numChannels int
+	sampleRate  int
+	// state holds the two delay elements of each section of each channel.
+	state [][][2]float64
+}
+
+// New returns a filter applying the cascade to a stream of the given format.
+// It returns an error if the format isn't valid, see audio.Format.Validate.
+func New(format *audio.Format, c Cascade) (*Filter, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	f := &Filter{
+		sections:    c,
+		numChannels: format.NumChannels,
+		sampleRate:  format.SampleRate,
+		state:       make([][][2]float64, format.NumChannels),
+	}
+	for ch := range f.state {
+		f.state[ch] = make([][2]float64, len(c))
+	}
+	return f, nil
+}
+
+// Process filters the frames of buf in place, see audio.TransformFloat. It
+// returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the sample rate of the filter.
+func (f *Filter) Process(buf audio.Buffer) error {
+	nc := f.numChannels
+	if err := audio.CheckFormat(buf, nc, f.sampleRate); err != nil {
+		return err
+	}
+	return audio.TransformFloat(buf, func(samples []float64, _ int) {
+		for ch := 0; ch < nc; ch++ {
+			state := f.state[ch]
+			for i := ch; i < len(samples); i += nc {
+				x := samples[i]
+				for s, b := range f.sections {
+					z := &state[s]
+					y := b.B0*x + z[0]
+					z[0] = b.B1*x - b.A1*y + z[1]
+					z[1] = b.B2*x - b.A2*y
+					x = y
+				}
+				samples[i] = x
+			}
+		}
+	})
+}
+
+// Reset clears the state of the filter, as if it never processed samples.
+func (f *Filter) Reset() {
+	for ch := range f.state {
+		clear(f.state[ch])
+	}
+}
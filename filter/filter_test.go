@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+const rate = 48000
+
+func TestBiquad_Response(t *testing.T) {
+	tests := []struct {
+		name   string
+		c      Cascade
+		freq   float64
+		wantDB float64
+	}{
+		{"low-pass pass band", Cascade{LowPass(rate, 1000, math.Sqrt2/2)}, 20, 0},
+		{"low-pass cutoff", Cascade{LowPass(rate, 1000, math.Sqrt2/2)}, 1000, -3.0103},
+		{"high-pass cutoff", Cascade{HighPass(rate, 1000, math.Sqrt2/2)}, 1000, -3.0103},
+		{"band-pass center", Cascade{BandPass(rate, 1000, 2)}, 1000, 0},
+		{"peaking center", Cascade{Peaking(rate, 1000, 1, 6)}, 1000, 6},
+		{"peaking far", Cascade{Peaking(rate, 1000, 1, 6)}, 20, 0},
+		{"low shelf", Cascade{LowShelf(rate, 200, math.Sqrt2/2, -12)}, 5, -12},
+		{"high shelf", Cascade{HighShelf(rate, 2000, math.Sqrt2/2, 12)}, 23000, 12},
+		{"all-pass", Cascade{AllPass(rate, 1000, 1)}, 3000, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.c.MagnitudeDB(rate, tt.freq); math.Abs(got-tt.wantDB) > 0.05 {
+			t.Errorf("%s: expected %v dB, got %v dB", tt.name, tt.wantDB, got)
+		}
+	}
+	if got := cmplx.Abs(Notch(rate, 1000, 1).Response(rate, 1000)); got > 1e-9 {
+		t.Errorf("expected the notch to reject its frequency, got %v", got)
+	}
+}
+
+func TestButterworth(t *testing.T) {
+	for order := 1; order <= 7; order++ {
+		lp, err := ButterworthLowPass(rate, 1000, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hp, err := ButterworthHighPass(rate, 1000, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lp) != (order+1)/2 {
+			t.Errorf("order %d: expected %d sections, got %d", order, (order+1)/2, len(lp))
+		}
+		if got := lp.MagnitudeDB(rate, 1000); math.Abs(got+3.0103) > 0.01 {
+			t.Errorf("order %d: expected -3 dB at the cutoff, got %v", order, got)
+		}
+		if got := hp.MagnitudeDB(rate, 1000); math.Abs(got+3.0103) > 0.01 {
+			t.Errorf("order %d: expected -3 dB at the cutoff, got %v", order, got)
+		}
+		// the attenuation an octave above the cutoff grows by 6 dB per order
+		if got := lp.MagnitudeDB(rate, 4000); got > -10*float64(order) {
+			t.Errorf("order %d: expected at least %d dB of attenuation, got %v", order, 10*order, got)
+		}
+	}
+	if _, err := ButterworthLowPass(rate, 1000, 0); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestLinkwitzRiley(t *testing.T) {
+	for _, order := range []int{2, 4, 6, 8, 10} {
+		lp, err := LinkwitzRileyLowPass(rate, 2000, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hp, err := LinkwitzRileyHighPass(rate, 2000, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lp.MagnitudeDB(rate, 2000); math.Abs(got+6.0206) > 0.01 {
+			t.Errorf("LR%d: expected -6 dB at the crossover, got %v", order, got)
+		}
+		for _, freq := range []float64{100, 1000, 2000, 5000, 15000} {
+			sum := lp.Response(rate, freq) + hp.Response(rate, freq)
+			if got := cmplx.Abs(sum); math.Abs(got-1) > 1e-6 {
+				t.Errorf("LR%d at %v Hz: expected a flat sum, got %v", order, freq, got)
+			}
+		}
+	}
+	if _, err := LinkwitzRileyLowPass(rate, 1000, 3); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestFilter_Process(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: rate}
+	c, err := ButterworthLowPass(rate, 1000, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// left: 200 Hz, right: 8 kHz
+	frames := rate / 10
+	buf := &audio.FloatBuffer{Format: format, Data: make([]float64, 2*frames)}
+	for i := 0; i < frames; i++ {
+		buf.Data[2*i] = math.Sin(2 * math.Pi * 200 * float64(i) / rate)
+		buf.Data[2*i+1] = math.Sin(2 * math.Pi * 8000 * float64(i) / rate)
+	}
+	whole := buf.Clone().(*audio.FloatBuffer)
+	f, err := New(format, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Process(whole); err != nil {
+		t.Fatal(err)
+	}
+	// processing the stream in chunks gives the same result
+	f.Reset()
+	for _, chunk := range buf.Split(333) {
+		if err := f.Process(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var left, right float64
+	for i := frames / 2; i < frames; i++ {
+		if buf.Data[2*i] != whole.Data[2*i] || buf.Data[2*i+1] != whole.Data[2*i+1] {
+			t.Fatalf("frame %d: streamed output differs", i)
+		}
+		left = math.Max(left, math.Abs(buf.Data[2*i]))
+		right = math.Max(right, math.Abs(buf.Data[2*i+1]))
+	}
+	if left < 0.99 || right > 0.001 {
+		t.Errorf("expected the 200 Hz tone to pass and the 8 kHz one to be cut, got peaks %v and %v", left, right)
+	}
+
+	// integer buffers are filtered in place too
+	ib := &audio.IntBuffer{Format: format, Data: []int{1000, 1000, 1000, 1000}, SourceBitDepth: 16}
+	if err := f.Process(ib); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Process(&audio.FloatBuffer{Format: audio.FormatStereo44100}); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package filter
+
+import (
+	"math"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/window"
+)
+
+// FIR applies a finite impulse response filter to the channels of a stream
+// using the direct form, which is efficient for short kernels. Long kernels,
+// such as reverb impulse responses, are better applied with the partitioned
+// convolution of the convolve package.
+type FIR struct {
+	taps        []float64
+	numChannels int
+	sampleRate  int
+	// history holds the last len(taps)-1 input samples of each channel,
+	// the most recent last.
+	history [][]float64
+}
+
+// NewFIR returns a filter convolving a stream of the given format with the
+// taps. It returns an error if the format isn't valid, see
+// audio.Format.Validate.
+func NewFIR(format *audio.Format, taps []float64) (*FIR, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	f := &FIR{
+		taps:        append([]float64(nil), taps...),
+		numChannels: format.NumChannels,
+		sampleRate:  format.SampleRate,
+		history:     make([][]float64, format.NumChannels),
+	}
+	for ch := range f.history {
+		f.history[ch] = make([]float64, max(0, len(taps)-1))
+	}
+	return f, nil
+}
+
+// Process filters the frames of buf in place, see audio.TransformFloat. It
+// returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the sample rate of the filter.
+func (f *FIR) Process(buf audio.Buffer) error {
+	nc := f.numChannels
+	if err := audio.CheckFormat(buf, nc, f.sampleRate); err != nil {
+		return err
+	}
+	return audio.TransformFloat(buf, func(samples []float64, _ int) {
+		frames := len(samples) / nc
+		for ch := 0; ch < nc; ch++ {
+			// x holds the history followed by the input samples
+			h := f.history[ch]
+			x := make([]float64, len(h)+frames)
+			copy(x, h)
+			for i := 0; i < frames; i++ {
+				x[len(h)+i] = samples[i*nc+ch]
+			}
+			for i := 0; i < frames; i++ {
+				var y float64
+				last := len(h) + i
+				for k, tap := range f.taps {
+					y += tap * x[last-k]
+				}
+				samples[i*nc+ch] = y
+			}
+			copy(h, x[len(x)-len(h):])
+		}
+	})
+}
+
+// Reset clears the state of the filter, as if it never processed samples.
+func (f *FIR) Reset() {
+	for ch := range f.history {
+		clear(f.history[ch])
+	}
+}
+
+// LowPassFIR returns the taps of a windowed-sinc low-pass filter cutting at
+// freq Hz, with a unity gain at DC. The transition band narrows as the number
+// of taps grows. w defaults to a Blackman window if nil.
+func LowPassFIR(rate, freq float64, taps int, w window.Func) []float64 {
+	h := sinc(taps, freq/rate, w)
+	normalizeDC(h)
+	return h
+}
+
+// HighPassFIR returns the taps of a windowed-sinc high-pass filter cutting at
+// freq Hz, obtained by spectral inversion of the low-pass filter. The number
+// of taps is rounded up to an odd number.
+func HighPassFIR(rate, freq float64, taps int, w window.Func) []float64 {
+	h := LowPassFIR(rate, freq, taps|1, w)
+	invert(h)
+	return h
+}
+
+// BandPassFIR returns the taps of a windowed-sinc band-pass filter letting
+// the frequencies between low and high Hz through, with a unity gain in the
+// pass band. The number of taps is rounded up to an odd number.
+func BandPassFIR(rate, low, high float64, taps int, w window.Func) []float64 {
+	h := BandStopFIR(rate, low, high, taps, w)
+	invert(h)
+	return h
+}
+
+// BandStopFIR returns the taps of a windowed-sinc band-stop filter rejecting
+// the frequencies between low and high Hz. The number of taps is rounded up
+// to an odd number.
+func BandStopFIR(rate, low, high float64, taps int, w window.Func) []float64 {
+	h := LowPassFIR(rate, low, taps|1, w)
+	hp := HighPassFIR(rate, high, taps|1, w)
+	for i := range h {
+		h[i] += hp[i]
+	}
+	return h
+}
+
+// sinc returns the windowed ideal low-pass impulse response of n taps, with
+// the cutoff frequency cutoff relative to the sample rate.
+func sinc(n int, cutoff float64, w window.Func) []float64 {
+	if w == nil {
+		w = window.Blackman
+	}
+	h := w(n)
+	center := float64(n-1) / 2
+	for i := range h {
+		x := float64(i) - center
+		if x == 0 {
+			h[i] *= 2 * cutoff
+		} else {
+			h[i] *= math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+	}
+	return h
+}
+
+// normalizeDC scales the taps so the gain at DC is 1.
+func normalizeDC(h []float64) {
+	var sum float64
+	for _, v := range h {
+		sum += v
+	}
+	for i := range h {
+		h[i] /= sum
+	}
+}
+
+// invert turns the odd length low-pass filter h into the complementary
+// high-pass filter.
+func invert(h []float64) {
+	for i := range h {
+		h[i] = -h[i]
+	}
+	h[len(h)/2]++
+}
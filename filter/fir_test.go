@@ -0,0 +1,73 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/window"
+)
+
+// firResponse returns the gain of the taps at freq Hz, in decibels.
+func firResponse(h []float64, freq float64) float64 {
+	var r complex128
+	for i, v := range h {
+		r += complex(v, 0) * cmplx.Rect(1, -2*math.Pi*freq*float64(i)/rate)
+	}
+	return 20 * math.Log10(cmplx.Abs(r))
+}
+
+func TestFIRDesign(t *testing.T) {
+	tests := []struct {
+		name string
+		taps []float64
+		pass []float64
+		stop []float64
+	}{
+		{"low-pass", LowPassFIR(rate, 4000, 201, nil), []float64{0, 1000, 3000}, []float64{6000, 12000}},
+		{"high-pass", HighPassFIR(rate, 4000, 200, window.Kaiser(8)), []float64{6000, 12000, 23000}, []float64{0, 1000, 2000}},
+		{"band-pass", BandPassFIR(rate, 2000, 6000, 201, nil), []float64{3500, 4000, 4500}, []float64{0, 500, 10000}},
+		{"band-stop", BandStopFIR(rate, 2000, 6000, 201, nil), []float64{0, 500, 10000}, []float64{3500, 4000, 4500}},
+	}
+	for _, tt := range tests {
+		if len(tt.taps)%2 != 1 {
+			t.Errorf("%s: expected an odd number of taps, got %d", tt.name, len(tt.taps))
+		}
+		for _, f := range tt.pass {
+			if got := firResponse(tt.taps, f); math.Abs(got) > 0.1 {
+				t.Errorf("%s: expected 0 dB at %v Hz, got %v", tt.name, f, got)
+			}
+		}
+		for _, f := range tt.stop {
+			if got := firResponse(tt.taps, f); got > -60 {
+				t.Errorf("%s: expected at least 60 dB of attenuation at %v Hz, got %v", tt.name, f, got)
+			}
+		}
+	}
+}
+
+func TestFIR_Process(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: rate}
+	f, err := NewFIR(format, []float64{0.5, 0.25, 0.125})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &audio.FloatBuffer{Format: format, Data: []float64{1, 0, 0, 1, 0, 0, 0, 0}}
+	// processing frame by frame keeps the history between calls
+	for _, chunk := range buf.Split(1) {
+		if err := f.Process(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []float64{0.5, 0, 0.25, 0.5, 0.125, 0.25, 0, 0.125}
+	for i := range want {
+		if buf.Data[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, buf.Data)
+		}
+	}
+	if err := f.Process(&audio.FloatBuffer{Format: audio.FormatMono44100}); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+}
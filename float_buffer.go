@@ -4,11 +4,15 @@ var _ Buffer = (*FloatBuffer)(nil)
 var _ Buffer = (*Float32Buffer)(nil)
 
 // FloatBuffer is an audio buffer with its PCM data formatted as float64.
+// It shares its layout with TypedBuffer[float64].
 type FloatBuffer struct {
 	// Format is the representation of the underlying data format
 	Format *Format
 	// Data is the buffer PCM data as floats
 	Data []float64
+	// SourceBitDepth helps us know if the source was encoded on
+	// 8, 16, 24, 32, 64 bits.
+	SourceBitDepth int
 }
 
 // PCMFormat returns the buffer format information.
@@ -19,30 +23,14 @@ func (buf *FloatBuffer) AsFloatBuffer() *FloatBuffer { return buf }
 
 // AsFloat32Buffer implements the Buffer interface and returns a float 32 version of itself.
 func (buf *FloatBuffer) AsFloat32Buffer() *Float32Buffer {
-	newB := &Float32Buffer{}
-	newB.Data = make([]float32, len(buf.Data))
-	for i := 0; i < len(buf.Data); i++ {
-		newB.Data[i] = float32(buf.Data[i])
-	}
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return buf.typed().AsFloat32Buffer()
 }
 
-// AsIntBuffer returns a copy of this buffer but with data truncated to Ints.
+// AsIntBuffer returns a copy of this buffer but with data scaled to ints of
+// SourceBitDepth bits (16 if not set), see Float64ToInt.
+// Use AsDitheredIntBuffer to reduce the quantization distortion.
 func (buf *FloatBuffer) AsIntBuffer() *IntBuffer {
-	newB := &IntBuffer{}
-	newB.Data = make([]int, len(buf.Data))
-	for i := 0; i < len(buf.Data); i++ {
-		newB.Data[i] = int(buf.Data[i])
-	}
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return buf.typed().AsIntBuffer()
 }
 
 // Clone creates a clean clone that can be modified without
@@ -51,30 +39,21 @@ func (buf *FloatBuffer) Clone() Buffer {
 	if buf == nil {
 		return nil
 	}
-	newB := &FloatBuffer{}
-	newB.Data = make([]float64, len(buf.Data))
-	copy(newB.Data, buf.Data)
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return (*FloatBuffer)(buf.typed().clone())
 }
 
 // NumFrames returns the number of frames contained in the buffer.
 func (buf *FloatBuffer) NumFrames() int {
-	if buf == nil || buf.Format == nil {
-		return 0
-	}
-	numChannels := buf.Format.NumChannels
-	if numChannels == 0 {
-		numChannels = 1
-	}
+	return buf.typed().NumFrames()
+}
 
-	return len(buf.Data) / numChannels
+// typed returns the buffer as a TypedBuffer sharing its data.
+func (buf *FloatBuffer) typed() *TypedBuffer[float64] {
+	return (*TypedBuffer[float64])(buf)
 }
 
 // Float32Buffer is an audio buffer with its PCM data formatted as float32.
+// It shares its layout with TypedBuffer[float32].
 type Float32Buffer struct {
 	// Format is the representation of the underlying data format
 	Format *Format
@@ -90,40 +69,17 @@ func (buf *Float32Buffer) PCMFormat() *Format { return buf.Format }
 
 // AsFloatBuffer implements the Buffer interface and returns a float64 version of itself.
 func (buf *Float32Buffer) AsFloatBuffer() *FloatBuffer {
-	newB := &FloatBuffer{}
-	newB.Data = make([]float64, len(buf.Data))
-	for i := 0; i < len(buf.Data); i++ {
-		newB.Data[i] = float64(buf.Data[i])
-	}
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return buf.typed().AsFloatBuffer()
 }
 
 // AsFloat32Buffer implements the Buffer interface and returns itself.
 func (buf *Float32Buffer) AsFloat32Buffer() *Float32Buffer { return buf }
 
-// AsIntBuffer returns a copy of this buffer but with data truncated to Ints.
-// It is usually recommended to apply a transforms when going from a 24bit source
-// to an int (16bit destination). Look at transforms.PCMScaleF32() for instance
+// AsIntBuffer returns a copy of this buffer but with data scaled to ints of
+// SourceBitDepth bits (16 if not set), see Float64ToInt.
+// Use AsDitheredIntBuffer to reduce the quantization distortion.
 func (buf *Float32Buffer) AsIntBuffer() *IntBuffer {
-	newB := &IntBuffer{SourceBitDepth: buf.SourceBitDepth}
-	if newB.SourceBitDepth == 0 {
-		newB.SourceBitDepth = 16
-	}
-	newB.Data = make([]int, len(buf.Data))
-	// TODO: we might want to consider checking the min/max values
-	// and if we are in a normalized float range, apply a denormalization.
-	for i := 0; i < len(buf.Data); i++ {
-		newB.Data[i] = int(buf.Data[i])
-	}
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return buf.typed().AsIntBuffer()
 }
 
 // Clone creates a clean clone that can be modified without
@@ -132,25 +88,15 @@ func (buf *Float32Buffer) Clone() Buffer {
 	if buf == nil {
 		return nil
 	}
-	newB := &Float32Buffer{}
-	newB.Data = make([]float32, len(buf.Data))
-	copy(newB.Data, buf.Data)
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return (*Float32Buffer)(buf.typed().clone())
 }
 
 // NumFrames returns the number of frames contained in the buffer.
 func (buf *Float32Buffer) NumFrames() int {
-	if buf == nil || buf.Format == nil {
-		return 0
-	}
-	numChannels := buf.Format.NumChannels
-	if numChannels == 0 {
-		numChannels = 1
-	}
+	return buf.typed().NumFrames()
+}
 
-	return len(buf.Data) / numChannels
+// typed returns the buffer as a TypedBuffer sharing its data.
+func (buf *Float32Buffer) typed() *TypedBuffer[float32] {
+	return (*TypedBuffer[float32])(buf)
 }
@@ -13,9 +13,9 @@ func TestFloat64Buffer(t *testing.T) {
 		f32     []float32
 		integer []int
 	}{
-		{"float 64 conversion", []float64{1, 2, 3}, []float32{1, 2, 3}, []int{1, 2, 3}},
-		{"float 64 conversion, max float32", []float64{1, 2, float64(math.MaxFloat32)}, []float32{1, 2, math.MaxFloat32}, []int{1, 2, int(math.Inf(1))}},
-		{"float 64 conversion, inf", []float64{1, 2, math.MaxFloat64}, []float32{1, 2, float32(math.Inf(1))}, []int{1, 2, int(math.Inf(1))}},
+		{"float 64 conversion", []float64{0.5, -0.25, 0}, []float32{0.5, -0.25, 0}, []int{16384, -8192, 0}},
+		{"float 64 conversion, max float32", []float64{0.5, -0.25, float64(math.MaxFloat32)}, []float32{0.5, -0.25, math.MaxFloat32}, []int{16384, -8192, 32767}},
+		{"float 64 conversion, inf", []float64{0.5, -0.25, math.MaxFloat64}, []float32{0.5, -0.25, float32(math.Inf(1))}, []int{16384, -8192, 32767}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -39,9 +39,9 @@ func TestClone(t *testing.T) {
 		f32     []float32
 		integer []int
 	}{
-		{"float 64 conversion", []float64{1, 2, 3}, []float32{1, 2, 3}, []int{1, 2, 3}},
-		{"float 64 conversion, max float32", []float64{1, 2, float64(math.MaxFloat32)}, []float32{1, 2, math.MaxFloat32}, []int{1, 2, int(math.Inf(1))}},
-		{"float 64 conversion, inf", []float64{1, 2, math.MaxFloat64}, []float32{1, 2, float32(math.Inf(1))}, []int{1, 2, int(math.Inf(1))}},
+		{"float 64 conversion", []float64{0.5, -0.25, 0}, []float32{0.5, -0.25, 0}, []int{16384, -8192, 0}},
+		{"float 64 conversion, max float32", []float64{0.5, -0.25, float64(math.MaxFloat32)}, []float32{0.5, -0.25, math.MaxFloat32}, []int{16384, -8192, 32767}},
+		{"float 64 conversion, inf", []float64{0.5, -0.25, math.MaxFloat64}, []float32{0.5, -0.25, float32(math.Inf(1))}, []int{16384, -8192, 32767}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -86,9 +86,9 @@ func TestFloat32Buffer(t *testing.T) {
 		f32     []float32
 		integer []int
 	}{
-		{"float 32 conversion", []float64{1, 2, 3}, []float32{1, 2, 3}, []int{1, 2, 3}},
-		{"float 32 conversion, max float32", []float64{1, 2, float64(math.MaxFloat32)}, []float32{1, 2, math.MaxFloat32}, []int{1, 2, int(math.Inf(1))}},
-		{"float 32 conversion, inf", []float64{1, 2, math.Inf(1)}, []float32{1, 2, float32(math.Inf(1))}, []int{1, 2, int(math.Inf(1))}},
+		{"float 32 conversion", []float64{0.5, -0.25, 0}, []float32{0.5, -0.25, 0}, []int{16384, -8192, 0}},
+		{"float 32 conversion, max float32", []float64{0.5, -0.25, float64(math.MaxFloat32)}, []float32{0.5, -0.25, math.MaxFloat32}, []int{16384, -8192, 32767}},
+		{"float 32 conversion, inf", []float64{0.5, -0.25, math.Inf(1)}, []float32{0.5, -0.25, float32(math.Inf(1))}, []int{16384, -8192, 32767}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -104,3 +104,26 @@ func TestFloat32Buffer(t *testing.T) {
 		})
 	}
 }
+
+func TestFloatBuffer_AsIntBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		bitDepth int
+		data     []float64
+		want     []int
+	}{
+		{"default 16 bit", 0, []float64{-1, -0.5, 0, 0.5, 1}, []int{-32768, -16384, 0, 16384, 32767}},
+		{"8 bit", 8, []float64{-1, -0.5, 0, 0.5, 1}, []int{-128, -64, 0, 64, 127}},
+		{"24 bit", 24, []float64{-1, 0, 1}, []int{-8388608, 0, 8388607}},
+		{"rounding", 8, []float64{0.5 / 128, -0.4 / 128, 1.6 / 128}, []int{1, 0, 2}},
+		{"clipping", 16, []float64{-2, 2, math.NaN()}, []int{-32768, 32767, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &FloatBuffer{Format: FormatMono44100, Data: tt.data, SourceBitDepth: tt.bitDepth}
+			if got := buf.AsIntBuffer().Data; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v got %v", tt.want, got)
+			}
+		})
+	}
+}
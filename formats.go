@@ -7,21 +7,25 @@ var (
 	FormatMono22500 = &Format{
 		NumChannels: 1,
 		SampleRate:  22500,
+		Layout:      LayoutMono,
 	}
 	// FormatMono44100 is mono 8bit 44.1kHz format.
 	FormatMono44100 = &Format{
 		NumChannels: 1,
 		SampleRate:  44100,
+		Layout:      LayoutMono,
 	}
 	// FormatMono48000 is mono 48kHz format.
 	FormatMono48000 = &Format{
 		NumChannels: 1,
 		SampleRate:  48000,
+		Layout:      LayoutMono,
 	}
 	// FormatMono96000 is mono 96kHz format.
 	FormatMono96000 = &Format{
 		NumChannels: 1,
 		SampleRate:  96000,
+		Layout:      LayoutMono,
 	}
 
 	// STEREO
@@ -30,20 +34,24 @@ var (
 	FormatStereo22500 = &Format{
 		NumChannels: 2,
 		SampleRate:  22500,
+		Layout:      LayoutStereo,
 	}
 	// FormatStereo44100 is stereo 8bit 44.1kHz format.
 	FormatStereo44100 = &Format{
 		NumChannels: 2,
 		SampleRate:  44100,
+		Layout:      LayoutStereo,
 	}
 	// FormatStereo48000 is stereo 48kHz format.
 	FormatStereo48000 = &Format{
 		NumChannels: 2,
 		SampleRate:  48000,
+		Layout:      LayoutStereo,
 	}
 	// FormatStereo96000 is stereo 96kHz format.
 	FormatStereo96000 = &Format{
 		NumChannels: 2,
 		SampleRate:  96000,
+		Layout:      LayoutStereo,
 	}
 )
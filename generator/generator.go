@@ -0,0 +1,131 @@
+/*
+Package generator synthesizes test and calibration signals: oscillators,
+noise, impulses, sweeps and constant levels.
+
+A Generator produces a signal one sample at a time and keeps its state, so
+consecutive calls to Fill, or a Reader, continue the signal without phase
+discontinuities. Generate and Fill write generators to buffers, converting
+the samples to the format of the buffer, while Reader streams them as an
+audio.Reader.
+*/
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/go-audio/audio"
+)
+
+var (
+	// ErrInvalidFrequency is returned when a frequency isn't supported by a
+	// generator.
+	ErrInvalidFrequency = errors.New("invalid frequency")
+	// ErrInvalidDuration is returned when a duration isn't strictly positive.
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+// Generator produces a signal one sample at a time.
+type Generator interface {
+	// Next returns the next sample of the signal, nominally in [-1, 1].
+	Next() float64
+}
+
+// Fill overwrites the samples of b with the signals of gens: a single
+// generator is written to every channel of b, otherwise gens must hold one
+// generator per channel. Integer samples saturate, see audio.TransformFloat.
+// It returns an error wrapping audio.ErrChannelMismatch if the number of
+// generators doesn't match the channels of b.
+func Fill(b audio.Buffer, gens ...Generator) error {
+	nc := 1
+	if b != nil {
+		if f := b.PCMFormat(); f != nil && f.NumChannels > 0 {
+			nc = f.NumChannels
+		}
+	}
+	if len(gens) != 1 && len(gens) != nc {
+		return fmt.Errorf("%w: %d generators for %d channels", audio.ErrChannelMismatch, len(gens), nc)
+	}
+	return audio.TransformFloat(b, func(samples []float64, _ int) {
+		for i := 0; i+nc <= len(samples); i += nc {
+			if len(gens) == 1 {
+				v := gens[0].Next()
+				for c := 0; c < nc; c++ {
+					samples[i+c] = v
+				}
+				continue
+			}
+			for c, g := range gens {
+				samples[i+c] = g.Next()
+			}
+		}
+	})
+}
+
+// Generate returns a buffer of the given format holding frames frames of the
+// signals of gens, see Fill. It returns an error if the format isn't valid,
+// see audio.Format.Validate.
+func Generate(format *audio.Format, frames int, gens ...Generator) (*audio.FloatBuffer, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	buf := &audio.FloatBuffer{Format: format, Data: make([]float64, max(0, frames)*format.NumChannels)}
+	if err := Fill(buf, gens...); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// checkRate returns an error wrapping audio.ErrInvalidSampleRate if rate
+// isn't a finite positive number.
+func checkRate(rate float64) error {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return fmt.Errorf("%w: %v Hz", audio.ErrInvalidSampleRate, rate)
+	}
+	return nil
+}
+
+// DC is a generator producing a constant level.
+type DC float64
+
+// Next implements the Generator interface.
+func (d DC) Next() float64 { return float64(d) }
+
+// Silence is a generator producing zeros.
+type Silence struct{}
+
+// Next implements the Generator interface.
+func (Silence) Next() float64 { return 0 }
+
+// Impulse is a generator producing unit impulses, either a single one or
+// a periodic train.
+type Impulse struct {
+	// Amplitude is the value of the impulses.
+	Amplitude float64
+	// Period is the number of samples between impulses, 0 for a single
+	// impulse.
+	Period int
+
+	n int
+}
+
+// NewImpulse returns a generator producing impulses of amplitude 1 every
+// period samples, or a single impulse if period is 0, starting with an
+// impulse.
+func NewImpulse(period int) *Impulse {
+	return &Impulse{Amplitude: 1, Period: period}
+}
+
+// Next implements the Generator interface.
+func (g *Impulse) Next() float64 {
+	n := g.n
+	g.n++
+	if n == 0 || (g.Period > 0 && n%g.Period == 0) {
+		return g.Amplitude
+	}
+	return 0
+}
+
+// Reset restarts the generator with an impulse.
+func (g *Impulse) Reset() { g.n = 0 }
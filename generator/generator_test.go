@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestFill(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: 8000}
+	buf := &audio.IntBuffer{Format: format, Data: make([]int, 8), SourceBitDepth: 16}
+	if err := Fill(buf, NewImpulse(2), DC(-0.5)); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{32767, -16384, 0, -16384, 32767, -16384, 0, -16384}
+	for i := range want {
+		if buf.Data[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, buf.Data)
+		}
+	}
+	if err := Fill(buf, Silence{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range buf.Data {
+		if v != 0 {
+			t.Fatalf("expected silence, got %v", buf.Data)
+		}
+	}
+	if err := Fill(buf, Silence{}, Silence{}, Silence{}); !errors.Is(err, audio.ErrChannelMismatch) {
+		t.Errorf("expected ErrChannelMismatch, got %v", err)
+	}
+	if _, err := Generate(&audio.Format{}, 10, Silence{}); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+}
+
+func TestReader(t *testing.T) {
+	format := &audio.Format{NumChannels: 1, SampleRate: 44100}
+	want, err := Generate(format, 1000, newOscillator(t, Sine, 44100, 997))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(format, 1000, newOscillator(t, Sine, 44100, 997))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reading in uneven chunks continues the phase
+	var got []float64
+	for {
+		buf := &audio.PCMBuffer{F64: make([]float64, 77), DataType: audio.DataTypeF64}
+		n, err := r.Read(buf)
+		got = append(got, buf.F64[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != len(want.Data) {
+		t.Fatalf("expected %d frames, got %d", len(want.Data), len(got))
+	}
+	for i := range got {
+		if got[i] != want.Data[i] {
+			t.Fatalf("frame %d: expected %v, got %v", i, want.Data[i], got[i])
+		}
+	}
+	stereo := &audio.PCMBuffer{Format: audio.FormatStereo44100, F64: make([]float64, 4), DataType: audio.DataTypeF64}
+	if _, err := r.Read(stereo); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	if _, err := r.Read(nil); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer for a nil buffer, got %v", err)
+	}
+	if _, err := r.Read(&audio.PCMBuffer{}); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer without a data type, got %v", err)
+	}
+}
+
+func TestNoise(t *testing.T) {
+	for _, c := range []Color{White, Pink, Brown} {
+		a, b := NewNoise(c, 42), NewNoise(c, 42)
+		first := make([]float64, 10000)
+		var sum, sumSquares float64
+		for i := range first {
+			first[i] = a.Next()
+			if v := b.Next(); v != first[i] {
+				t.Fatalf("%v: sample %d differs between generators of the same seed", c, i)
+			}
+			sum += first[i]
+			sumSquares += first[i] * first[i]
+		}
+		a.Reset()
+		for i := range first {
+			if v := a.Next(); v != first[i] {
+				t.Fatalf("%v: sample %d differs after a reset", c, i)
+			}
+		}
+		mean, rms := sum/10000, math.Sqrt(sumSquares/10000)
+		if math.Abs(mean) > 0.1 || rms < 0.1 || rms > 0.6 {
+			t.Errorf("%v: unexpected mean %v and RMS level %v", c, mean, rms)
+		}
+	}
+	if NewNoise(White, 1).Next() == NewNoise(White, 2).Next() {
+		t.Error("expected different seeds to produce different noise")
+	}
+}
@@ -0,0 +1,82 @@
+package generator
+
+import "math/rand"
+
+// Color is the spectral shape of a noise.
+type Color int
+
+const (
+	// White noise has a flat spectrum.
+	White Color = iota
+	// Pink noise falls by 3 dB per octave, carrying the same power in
+	// every octave.
+	Pink
+	// Brown noise falls by 6 dB per octave.
+	Brown
+)
+
+// String returns the name of the color.
+func (c Color) String() string {
+	switch c {
+	case White:
+		return "white"
+	case Pink:
+		return "pink"
+	case Brown:
+		return "brown"
+	}
+	return "unknown"
+}
+
+// Noise is a generator producing pseudo-random noise. The noise is
+// deterministic: generators created with the same seed produce the same
+// samples.
+type Noise struct {
+	// Color is the spectral shape of the noise.
+	Color Color
+	// Amplitude scales the noise, white noise being uniformly distributed
+	// in [-Amplitude, Amplitude).
+	Amplitude float64
+
+	seed int64
+	rng  *rand.Rand
+	// state holds the filter states shaping white noise
+	state [7]float64
+}
+
+// NewNoise returns a generator of amplitude 1 producing noise of the color
+// c from the given seed.
+func NewNoise(c Color, seed int64) *Noise {
+	return &Noise{Color: c, Amplitude: 1, seed: seed, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Next implements the Generator interface.
+func (n *Noise) Next() float64 {
+	w := 2*n.rng.Float64() - 1
+	s := &n.state
+	switch n.Color {
+	case Pink:
+		// Paul Kellet's refined filter, within 0.05 dB of -3 dB per
+		// octave above 9 Hz at 44.1 kHz
+		s[0] = 0.99886*s[0] + w*0.0555179
+		s[1] = 0.99332*s[1] + w*0.0750759
+		s[2] = 0.96900*s[2] + w*0.1538520
+		s[3] = 0.86650*s[3] + w*0.3104856
+		s[4] = 0.55000*s[4] + w*0.5329522
+		s[5] = -0.7616*s[5] - w*0.0168980
+		p := s[0] + s[1] + s[2] + s[3] + s[4] + s[5] + s[6] + w*0.5362
+		s[6] = w * 0.115926
+		w = p * 0.11
+	case Brown:
+		// leaky integrator, the leak removing the DC drift
+		s[0] = (s[0] + 0.02*w) / 1.02
+		w = s[0] * 3.5
+	}
+	return n.Amplitude * w
+}
+
+// Reset restarts the noise sequence from the seed.
+func (n *Noise) Reset() {
+	n.rng.Seed(n.seed)
+	n.state = [7]float64{}
+}
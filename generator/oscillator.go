@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+)
+
+// Waveform is the shape of the signal of an oscillator.
+type Waveform int
+
+const (
+	// Sine is a sine wave.
+	Sine Waveform = iota
+	// Square is a square wave with a 50% duty cycle.
+	Square
+	// Saw is a rising sawtooth wave.
+	Saw
+	// Triangle is a triangle wave.
+	Triangle
+)
+
+// String returns the name of the waveform.
+func (w Waveform) String() string {
+	switch w {
+	case Sine:
+		return "sine"
+	case Square:
+		return "square"
+	case Saw:
+		return "saw"
+	case Triangle:
+		return "triangle"
+	}
+	return "unknown"
+}
+
+// Oscillator is a generator producing a periodic waveform. Phase 0 is the
+// rising zero crossing of the sine, saw and triangle waves and the rising
+// edge of the square wave. The frequency and the amplitude can be changed
+// between samples without phase discontinuities.
+type Oscillator struct {
+	// Waveform is the shape of the signal.
+	Waveform Waveform
+	// Freq is the frequency in Hz.
+	Freq float64
+	// Amplitude is the peak level of the signal.
+	Amplitude float64
+	// BandLimited reduces the aliasing of the discontinuities of the
+	// square, saw and triangle waves with polynomial band-limited steps
+	// (PolyBLEP) and ramps (PolyBLAMP).
+	BandLimited bool
+
+	rate  float64
+	phase float64 // in cycles, in [0, 1)
+}
+
+// NewOscillator returns an oscillator of amplitude 1 producing the waveform w
+// at freq Hz for the sample rate rate, without band limiting. It returns an
+// error wrapping audio.ErrInvalidSampleRate if rate isn't a finite positive
+// number, or an error wrapping ErrInvalidFrequency if freq isn't finite.
+func NewOscillator(w Waveform, rate, freq float64) (*Oscillator, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(freq) || math.IsInf(freq, 0) {
+		return nil, fmt.Errorf("%w: %v Hz", ErrInvalidFrequency, freq)
+	}
+	return &Oscillator{Waveform: w, Freq: freq, Amplitude: 1, rate: rate}, nil
+}
+
+// Next implements the Generator interface.
+func (o *Oscillator) Next() float64 {
+	t, dt := o.phase, math.Abs(o.Freq/o.rate)
+	var y float64
+	switch o.Waveform {
+	case Sine:
+		y = math.Sin(2 * math.Pi * t)
+	case Square:
+		y = 1
+		if t >= 0.5 {
+			y = -1
+		}
+		if o.BandLimited {
+			y += polyBLEP(t, dt) - polyBLEP(wrap(t+0.5), dt)
+		}
+	case Saw:
+		u := wrap(t + 0.5)
+		y = 2*u - 1
+		if o.BandLimited {
+			y -= polyBLEP(u, dt)
+		}
+	case Triangle:
+		u := wrap(t + 0.25)
+		y = 1 - 2*math.Abs(2*u-1)
+		if o.BandLimited {
+			// the slope changes by 8 per cycle at the troughs and
+			// peaks
+			y += 8 * dt * (polyBLAMP(u, dt) - polyBLAMP(wrap(u+0.5), dt))
+		}
+	}
+	o.phase = wrap(o.phase + o.Freq/o.rate)
+	return o.Amplitude * y
+}
+
+// Reset restarts the oscillator at phase 0.
+func (o *Oscillator) Reset() { o.phase = 0 }
+
+// wrap returns the fractional part of x, in [0, 1).
+func wrap(x float64) float64 {
+	x -= math.Floor(x)
+	if x >= 1 {
+		return 0
+	}
+	return x
+}
+
+// polyBLEP returns the correction turning the unit step at phase 0 of a
+// naive waveform into a band-limited step, t being the phase in cycles and
+// dt the phase increment per sample.
+func polyBLEP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		t /= dt
+		return 2*t - t*t - 1
+	case t > 1-dt:
+		t = (t - 1) / dt
+		return t*t + 2*t + 1
+	}
+	return 0
+}
+
+// polyBLAMP returns the correction turning a unit change of slope per sample
+// at phase 0 into a band-limited ramp, the integral of polyBLEP.
+func polyBLAMP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		t = t/dt - 1
+		return -t * t * t / 3
+	case t > 1-dt:
+		t = (t-1)/dt + 1
+		return t * t * t / 3
+	}
+	return 0
+}
+
+// Multitone is a generator summing sine waves of equal amplitude.
+type Multitone struct {
+	// Amplitude is the peak level the signal can reach.
+	Amplitude float64
+
+	tones []*Oscillator
+}
+
+// NewMultitone returns a generator summing sine waves at the frequencies
+// freqs in Hz for the sample rate rate, each of amplitude 1/len(freqs) so
+// the peak level doesn't exceed 1. It returns the errors of NewOscillator.
+func NewMultitone(rate float64, freqs ...float64) (*Multitone, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+	m := &Multitone{Amplitude: 1}
+	for _, f := range freqs {
+		o, err := NewOscillator(Sine, rate, f)
+		if err != nil {
+			return nil, err
+		}
+		m.tones = append(m.tones, o)
+	}
+	return m, nil
+}
+
+// Next implements the Generator interface.
+func (m *Multitone) Next() float64 {
+	if len(m.tones) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, o := range m.tones {
+		sum += o.Next()
+	}
+	return m.Amplitude * sum / float64(len(m.tones))
+}
+
+// Reset restarts the sine waves at phase 0.
+func (m *Multitone) Reset() {
+	for _, o := range m.tones {
+		o.Reset()
+	}
+}
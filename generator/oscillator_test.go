@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+	"time"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/fft"
+)
+
+// aliasing returns the ratio in decibels of the power of the spectrum of n
+// samples of g outside the harmonics of freq to the total power.
+func aliasing(g Generator, rate, freq float64, n int) float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = g.Next()
+	}
+	spec, _ := fft.RealFFT(x)
+	var total, alias float64
+	for k, v := range spec {
+		p := cmplx.Abs(v) * cmplx.Abs(v)
+		total += p
+		if f := float64(k) * rate / float64(n); math.Abs(f/freq-math.Round(f/freq)) > 1e-6 {
+			alias += p
+		}
+	}
+	return 10 * math.Log10(alias/total)
+}
+
+func TestOscillator(t *testing.T) {
+	const rate = 48000
+	// 2015.625 Hz falls on a bin of a 4096 samples spectrum but doesn't
+	// divide the sample rate, so the aliases land between the harmonics
+	const freq = 2015.625
+	for _, tt := range []struct {
+		w         Waveform
+		reduction float64 // in dB
+	}{
+		// the harmonics of the triangle wave fall faster, leaving less
+		// aliasing to remove
+		{Square, 10}, {Saw, 10}, {Triangle, 2},
+	} {
+		naive := aliasing(newOscillator(t, tt.w, rate, freq), rate, freq, 4096)
+		o := newOscillator(t, tt.w, rate, freq)
+		o.BandLimited = true
+		bl := aliasing(o, rate, freq, 4096)
+		if bl > naive-tt.reduction {
+			t.Errorf("%v: expected band limiting to reduce aliasing by %v dB, from %.1f dB to %.1f dB", tt.w, tt.reduction, naive, bl)
+		}
+	}
+
+	tests := []struct {
+		w    Waveform
+		want []float64 // at phases 0, 1/8, 1/4 and 3/4
+	}{
+		{Sine, []float64{0, math.Sqrt2 / 2, 1, -1}},
+		{Square, []float64{1, 1, 1, -1}},
+		{Saw, []float64{0, 0.25, 0.5, -0.5}},
+		{Triangle, []float64{0, 0.5, 1, -1}},
+	}
+	for _, tt := range tests {
+		o := newOscillator(t, tt.w, 8, 1)
+		var got []float64
+		for i := 0; i < 8; i++ {
+			if v := o.Next(); i == 0 || i == 1 || i == 2 || i == 6 {
+				got = append(got, v)
+			}
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-12 {
+				t.Errorf("%v: expected %v, got %v", tt.w, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	const rate = 48000
+	for _, mode := range []SweepMode{LinearSweep, ExponentialSweep} {
+		s, err := NewSweep(mode, rate, 100, 1000, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Frames() != rate {
+			t.Fatalf("expected %d frames, got %d", rate, s.Frames())
+		}
+		// count the rising zero crossings of the last 100 ms of the
+		// sweep and of the 100 ms that follow
+		var prev float64
+		var crossings [2]int
+		for i := 0; i < rate+rate/10; i++ {
+			v := s.Next()
+			if i >= rate-rate/10 && prev < 0 && v >= 0 {
+				crossings[i/rate]++
+			}
+			prev = v
+		}
+		if crossings[1] != 100 {
+			t.Errorf("%d: expected 100 cycles after the sweep, got %d", mode, crossings[1])
+		}
+		// the linear sweep averages 955 Hz over its last 100 ms, the
+		// exponential one 891 Hz
+		want := map[SweepMode]int{LinearSweep: 95, ExponentialSweep: 89}[mode]
+		if crossings[0] < want-1 || crossings[0] > want+1 {
+			t.Errorf("%d: expected about %d cycles in the last 100 ms of the sweep, got %d", mode, want, crossings[0])
+		}
+	}
+	errs := []struct {
+		name     string
+		rate     float64
+		from, to float64
+		d        time.Duration
+		err      error
+	}{
+		{"exponential from 0 Hz", rate, 0, 1000, time.Second, ErrInvalidFrequency},
+		{"NaN frequency", rate, math.NaN(), 1000, time.Second, ErrInvalidFrequency},
+		{"infinite frequency", rate, 100, math.Inf(1), time.Second, ErrInvalidFrequency},
+		{"zero duration", rate, 100, 1000, 0, ErrInvalidDuration},
+		{"zero rate", 0, 100, 1000, time.Second, audio.ErrInvalidSampleRate},
+		{"NaN rate", math.NaN(), 100, 1000, time.Second, audio.ErrInvalidSampleRate},
+	}
+	for _, tt := range errs {
+		if _, err := NewSweep(ExponentialSweep, tt.rate, tt.from, tt.to, tt.d); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestNewOscillator_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		rate, freq float64
+		err        error
+	}{
+		{"zero rate", 0, 440, audio.ErrInvalidSampleRate},
+		{"negative rate", -44100, 440, audio.ErrInvalidSampleRate},
+		{"infinite rate", math.Inf(1), 440, audio.ErrInvalidSampleRate},
+		{"NaN frequency", 44100, math.NaN(), ErrInvalidFrequency},
+	}
+	for _, tt := range tests {
+		if _, err := NewOscillator(Sine, tt.rate, tt.freq); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+	if _, err := NewMultitone(0, 440); !errors.Is(err, audio.ErrInvalidSampleRate) {
+		t.Errorf("expected ErrInvalidSampleRate, got %v", err)
+	}
+}
+
+func newOscillator(t *testing.T, w Waveform, rate, freq float64) *Oscillator {
+	t.Helper()
+	o, err := NewOscillator(w, rate, freq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestMultitone(t *testing.T) {
+	m, err := NewMultitone(8, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Next()
+	// sin(π/4)/2 + sin(π/2)/2
+	if got := m.Next(); math.Abs(got-(math.Sqrt2/4+0.5)) > 1e-12 {
+		t.Errorf("expected %v, got %v", math.Sqrt2/4+0.5, got)
+	}
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+var _ audio.Reader = (*Reader)(nil)
+
+// Reader streams the signals of generators as an audio.Reader.
+type Reader struct {
+	format *audio.Format
+	gens   []Generator
+	// remaining is the number of frames left to read, negative for an
+	// endless stream.
+	remaining int
+}
+
+// NewReader returns a reader streaming frames frames of the signals of gens,
+// see Fill, in the given format. A negative number of frames makes an endless
+// stream. It returns an error if the format isn't valid, see
+// audio.Format.Validate, or an error wrapping audio.ErrChannelMismatch if
+// the number of generators doesn't match the channels of the format.
+func NewReader(format *audio.Format, frames int, gens ...Generator) (*Reader, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if len(gens) != 1 && len(gens) != format.NumChannels {
+		return nil, fmt.Errorf("%w: %d generators for %d channels", audio.ErrChannelMismatch, len(gens), format.NumChannels)
+	}
+	return &Reader{format: format, gens: gens, remaining: frames}, nil
+}
+
+// Read implements the audio.Reader interface, converting the samples to the
+// data type of buf. It returns an error wrapping audio.ErrInvalidBuffer if buf
+// is nil or has no data type, and an error wrapping audio.ErrFormatMismatch
+// if its format isn't compatible with the stream's, see
+// audio.Format.Compatible.
+func (r *Reader) Read(buf *audio.PCMBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, fmt.Errorf("%w: reading into a nil buffer", audio.ErrInvalidBuffer)
+	}
+	if buf.Format == nil {
+		f := *r.format
+		buf.Format = &f
+	}
+	if buf.DataType == audio.DataTypeUnknown {
+		return 0, fmt.Errorf("%w: reading into a buffer without a data type", audio.ErrInvalidBuffer)
+	}
+	if err := r.format.Compatible(buf.Format); err != nil {
+		return 0, err
+	}
+	n = buf.NumFrames()
+	if n == 0 {
+		return 0, nil
+	}
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	if r.remaining > 0 {
+		n = min(n, r.remaining)
+		r.remaining -= n
+	}
+	if err := Fill(buf.Slice(0, n), r.gens...); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
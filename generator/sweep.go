@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SweepMode is the way the frequency of a sweep changes over time.
+type SweepMode int
+
+const (
+	// LinearSweep changes the frequency by the same number of Hz per
+	// second.
+	LinearSweep SweepMode = iota
+	// ExponentialSweep changes the frequency by the same number of octaves
+	// per second, spending the same time in every octave. It is also known
+	// as a logarithmic sweep.
+	ExponentialSweep
+)
+
+// Sweep is a generator producing a sine wave whose frequency goes from one
+// frequency to another, also known as a chirp. The phase is computed
+// analytically so it stays accurate over long sweeps. Once the sweep is
+// over, the sine wave continues at the final frequency.
+type Sweep struct {
+	// Amplitude is the peak level of the signal.
+	Amplitude float64
+
+	mode         SweepMode
+	rate         float64
+	from, to     float64
+	duration     float64 // in seconds
+	frames       int
+	n            int
+	endPhase     float64 // in cycles, at the end of the sweep
+	logFreqRatio float64
+}
+
+// NewSweep returns a generator of amplitude 1 sweeping from the frequency
+// from to the frequency to, in Hz, over the duration d for the sample rate
+// rate. It returns an error wrapping ErrInvalidFrequency if the frequencies
+// are negative or not finite, or not positive for an exponential sweep, an
+// error wrapping ErrInvalidDuration if d isn't positive and an error wrapping
+// audio.ErrInvalidSampleRate if rate isn't a finite positive number.
+func NewSweep(mode SweepMode, rate, from, to float64, d time.Duration) (*Sweep, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+	if !(from >= 0 && to >= 0) || math.IsInf(from, 1) || math.IsInf(to, 1) || (mode == ExponentialSweep && (from == 0 || to == 0)) {
+		return nil, fmt.Errorf("%w: sweeping from %v Hz to %v Hz", ErrInvalidFrequency, from, to)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("%w: sweeping for %v", ErrInvalidDuration, d)
+	}
+	s := &Sweep{
+		Amplitude:    1,
+		mode:         mode,
+		rate:         rate,
+		from:         from,
+		to:           to,
+		duration:     d.Seconds(),
+		frames:       int(math.Round(d.Seconds() * rate)),
+		logFreqRatio: math.Log(to / from),
+	}
+	s.endPhase = s.cycles(s.duration)
+	return s, nil
+}
+
+// Frames returns the number of samples of the sweep.
+func (s *Sweep) Frames() int { return s.frames }
+
+// cycles returns the phase in cycles at the time t in seconds, not after the
+// end of the sweep.
+func (s *Sweep) cycles(t float64) float64 {
+	if s.duration <= 0 {
+		return 0
+	}
+	if s.mode == ExponentialSweep && s.logFreqRatio != 0 {
+		k := s.logFreqRatio / s.duration
+		return s.from / k * math.Expm1(k*t)
+	}
+	return s.from*t + (s.to-s.from)*t*t/(2*s.duration)
+}
+
+// Next implements the Generator interface.
+func (s *Sweep) Next() float64 {
+	t := float64(s.n) / s.rate
+	s.n++
+	var c float64
+	if t <= s.duration {
+		c = s.cycles(t)
+	} else {
+		c = s.endPhase + s.to*(t-s.duration)
+	}
+	return s.Amplitude * math.Sin(2*math.Pi*wrap(c))
+}
+
+// Reset restarts the sweep.
+func (s *Sweep) Reset() { s.n = 0 }
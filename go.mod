@@ -1 +1,3 @@
 module github.com/go-audio/audio
+
+go 1.21
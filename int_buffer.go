@@ -1,76 +1,39 @@
 package audio
 
-import "math"
-
 var _ Buffer = (*IntBuffer)(nil)
 
+// DefaultBitDepth is the bit depth assumed for the samples of an IntBuffer
+// when neither its SourceBitDepth nor its format's bit depth is set, and the
+// bit depth float samples are scaled to by default when converted to ints.
+// Use DetectBitDepth to estimate the bit depth of unknown samples.
+const DefaultBitDepth = 16
+
 // IntBuffer is an audio buffer with its PCM data formatted as int.
+// It shares its layout with TypedBuffer[int].
 type IntBuffer struct {
 	// Format is the representation of the underlying data format
 	Format *Format
 	// Data is the buffer PCM data as ints
 	Data []int
 	// SourceBitDepth helps us know if the source was encoded on
-	// 8, 16, 24, 32, 64 bits.
+	// 8, 16, 24, 32, 64 bits. If not set, the bit depth of the format's
+	// sample encoding is used, defaulting to DefaultBitDepth.
 	SourceBitDepth int
 }
 
 // PCMFormat returns the buffer format information.
 func (buf *IntBuffer) PCMFormat() *Format { return buf.Format }
 
-// AsFloatBuffer returns a copy of this buffer but with data converted to floats.
+// AsFloatBuffer returns a copy of this buffer but with data converted to
+// floats normalized to the [-1, 1) range based on the source bit depth.
 func (buf *IntBuffer) AsFloatBuffer() *FloatBuffer {
-	newB := &FloatBuffer{}
-	newB.Data = make([]float64, len(buf.Data))
-	for i := 0; i < len(buf.Data); i++ {
-		newB.Data[i] = float64(buf.Data[i])
-	}
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return buf.typed().AsFloatBuffer()
 }
 
-// AsFloat32Buffer returns a copy of this buffer but with data converted to float 32.
+// AsFloat32Buffer returns a copy of this buffer but with data converted to
+// float 32s normalized to the [-1, 1) range based on the source bit depth.
 func (buf *IntBuffer) AsFloat32Buffer() *Float32Buffer {
-	newB := &Float32Buffer{}
-	newB.Data = make([]float32, len(buf.Data))
-	max := int64(0)
-	// try to guess the bit depths without knowing the source
-	if buf.SourceBitDepth == 0 {
-		for _, s := range buf.Data {
-			if int64(s) > max {
-				max = int64(s)
-			}
-		}
-		buf.SourceBitDepth = 8
-		if max > 127 {
-			buf.SourceBitDepth = 16
-		}
-		// greater than int16, expecting int24
-		if max > 32767 {
-			buf.SourceBitDepth = 24
-		}
-		// int 32
-		if max > 8388607 {
-			buf.SourceBitDepth = 32
-		}
-		// int 64
-		if max > 4294967295 {
-			buf.SourceBitDepth = 64
-		}
-	}
-	newB.SourceBitDepth = buf.SourceBitDepth
-	factor := math.Pow(2, float64(buf.SourceBitDepth)-1)
-	for i := 0; i < len(buf.Data); i++ {
-		newB.Data[i] = float32(float64(buf.Data[i]) / factor)
-	}
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
-	}
-	return newB
+	return buf.typed().AsFloat32Buffer()
 }
 
 // AsIntBuffer implements the Buffer interface and returns itself.
@@ -78,15 +41,7 @@ func (buf *IntBuffer) AsIntBuffer() *IntBuffer { return buf }
 
 // NumFrames returns the number of frames contained in the buffer.
 func (buf *IntBuffer) NumFrames() int {
-	if buf == nil || buf.Format == nil {
-		return 0
-	}
-	numChannels := buf.Format.NumChannels
-	if numChannels == 0 {
-		numChannels = 1
-	}
-
-	return len(buf.Data) / numChannels
+	return (*TypedBuffer[int])(buf).NumFrames()
 }
 
 // Clone creates a clean clone that can be modified without
@@ -95,12 +50,23 @@ func (buf *IntBuffer) Clone() Buffer {
 	if buf == nil {
 		return nil
 	}
-	newB := &IntBuffer{}
-	newB.Data = make([]int, len(buf.Data))
-	copy(newB.Data, buf.Data)
-	newB.Format = &Format{
-		NumChannels: buf.Format.NumChannels,
-		SampleRate:  buf.Format.SampleRate,
+	return (*IntBuffer)((*TypedBuffer[int])(buf).clone())
+}
+
+// typed returns a TypedBuffer sharing the buffer's data with its bit depth
+// set, see bitDepth.
+func (buf *IntBuffer) typed() *TypedBuffer[int] {
+	return &TypedBuffer[int]{Format: buf.Format, Data: buf.Data, SourceBitDepth: buf.bitDepth()}
+}
+
+// bitDepth returns the bit depth of the samples: SourceBitDepth, the format's
+// bit depth or DefaultBitDepth.
+func (buf *IntBuffer) bitDepth() int {
+	if buf.SourceBitDepth != 0 {
+		return buf.SourceBitDepth
+	}
+	if bitDepth := buf.Format.bitDepth(); bitDepth != 0 {
+		return bitDepth
 	}
-	return newB
+	return DefaultBitDepth
 }
@@ -1,6 +1,7 @@
 package audio
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -39,3 +40,44 @@ func TestIntBuffer_AsFloat32Buffer(t *testing.T) {
 		})
 	}
 }
+
+func TestIntBuffer_RoundTrip(t *testing.T) {
+	for _, bitDepth := range []int{8, 16, 24, 32} {
+		max := 1<<uint(bitDepth-1) - 1
+		min := -max - 1
+		data := []int{min, min + 1, -1, 0, 1, max / 3, max - 1, max}
+		buf := &IntBuffer{Format: FormatStereo44100, Data: data, SourceBitDepth: bitDepth}
+
+		fb := buf.AsFloatBuffer()
+		for i, f := range fb.Data {
+			if f < -1.0 || f >= 1.0 {
+				t.Errorf("%d-bit: %d was converted out of range to %f", bitDepth, data[i], f)
+			}
+		}
+		if fb.Data[0] != -1 {
+			t.Errorf("%d-bit: expected the min value to be converted to -1, got %f", bitDepth, fb.Data[0])
+		}
+		if got := fb.AsIntBuffer(); !reflect.DeepEqual(got.Data, data) || got.SourceBitDepth != bitDepth {
+			t.Errorf("%d-bit float64 round trip: expected %v got %v (%d-bit)", bitDepth, data, got.Data, got.SourceBitDepth)
+		}
+		if bitDepth > 24 {
+			// float32 can't hold more than 24 bits of precision
+			continue
+		}
+		if got := buf.AsFloat32Buffer().AsIntBuffer(); !reflect.DeepEqual(got.Data, data) {
+			t.Errorf("%d-bit float32 round trip: expected %v got %v", bitDepth, data, got.Data)
+		}
+	}
+}
+
+func TestIntBuffer_AsFloatBuffer_KeepsSource(t *testing.T) {
+	buf := &IntBuffer{Format: FormatMono44100, Data: []int{-32768, 0, 16384}}
+	got := buf.AsFloatBuffer()
+	if buf.SourceBitDepth != 0 {
+		t.Errorf("the source buffer shouldn't be modified, SourceBitDepth set to %d", buf.SourceBitDepth)
+	}
+	want := []float64{-1, 0, 0.5}
+	if !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("expected %v got %v", want, got.Data)
+	}
+}
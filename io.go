@@ -0,0 +1,369 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// copyBufferSize is the number of samples of the buffer allocated by Copy.
+const copyBufferSize = 8192
+
+// Reader is the interface that wraps the basic Read method.
+//
+// Read reads up to buf.NumFrames() frames into buf, using the sample store
+// selected by buf.DataType. It returns the number of frames read
+// (0 <= n <= buf.NumFrames()) and any error encountered. If buf.Format is nil,
+// the reader sets it to the format of the stream.
+//
+// Like io.Reader, when a Reader has no more frames to return, it returns
+// io.EOF. Callers should always process the n > 0 frames returned before
+// considering the error.
+type Reader interface {
+	Read(buf *PCMBuffer) (n int, err error)
+}
+
+// Writer is the interface that wraps the basic Write method.
+//
+// Write writes all the frames of buf to the underlying stream. It returns the
+// number of frames written (0 <= n <= buf.NumFrames()) and any error
+// encountered that caused the write to stop early. Write must return a non-nil
+// error if it returns n < buf.NumFrames().
+type Writer interface {
+	Write(buf *PCMBuffer) (n int, err error)
+}
+
+// FloatReader is the interface that wraps the ReadFloat method.
+//
+// ReadFloat is the float counterpart of Reader.Read: it reads up to
+// buf.NumFrames() frames into buf, samples being normalized to the [-1, 1)
+// range, and follows the same conventions.
+type FloatReader interface {
+	ReadFloat(buf *FloatBuffer) (n int, err error)
+}
+
+// FloatWriter is the interface that wraps the WriteFloat method.
+//
+// WriteFloat is the float counterpart of Writer.Write, writing normalized
+// samples.
+type FloatWriter interface {
+	WriteFloat(buf *FloatBuffer) (n int, err error)
+}
+
+// Seeker is the interface that wraps the basic Seek method.
+//
+// Seek sets the frame offset for the next Read or Write to offset,
+// interpreted according to whence: io.SeekStart, io.SeekCurrent or io.SeekEnd.
+// Seek returns the new offset in frames relative to the start of the stream.
+type Seeker interface {
+	Seek(offset int64, whence int) (int64, error)
+}
+
+// ReadWriter is the interface that groups the basic Read and Write methods.
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+// ReadSeeker is the interface that groups the basic Read and Seek methods.
+type ReadSeeker interface {
+	Reader
+	Seeker
+}
+
+// Copy copies frames from src to dst until either EOF is reached on src or
+// an error occurs. It returns the number of frames copied and the first error
+// encountered while copying, if any. A successful Copy returns err == nil,
+// not err == io.EOF.
+//
+// Copy allocates a float64 buffer to move the frames, use CopyBuffer to
+// control the size and data type of the intermediate buffer.
+func Copy(dst Writer, src Reader) (written int64, err error) {
+	return CopyBuffer(dst, src, nil)
+}
+
+// CopyBuffer is identical to Copy except that it stages through the
+// provided buffer (if one is required) rather than allocating a temporary
+// one. If buf is nil, one is allocated; otherwise if it has no room for a
+// single frame, CopyBuffer panics.
+func CopyBuffer(dst Writer, src Reader, buf *PCMBuffer) (written int64, err error) {
+	if buf == nil {
+		buf = &PCMBuffer{F64: make([]float64, copyBufferSize), DataType: DataTypeF64}
+	} else if buf.Len() == 0 {
+		panic("empty buffer in CopyBuffer")
+	}
+	for {
+		nr, er := src.Read(buf)
+		if nr > 0 {
+			nw, ew := dst.Write(buf.Slice(0, nr))
+			if nw < 0 || nr < nw {
+				nw = 0
+				if ew == nil {
+					ew = errors.New("invalid write result")
+				}
+			}
+			written += int64(nw)
+			if ew != nil {
+				err = ew
+				break
+			}
+			if nr != nw {
+				err = io.ErrShortWrite
+				break
+			}
+		}
+		if er != nil {
+			if er != io.EOF {
+				err = er
+			}
+			break
+		}
+	}
+	return written, err
+}
+
+// ReadFull reads exactly buf.NumFrames() frames from r into buf.
+// It returns the number of frames copied and an error if fewer frames were
+// read. The error is io.EOF only if no frames were read. If an EOF happens
+// after reading some but not all the frames, ReadFull returns
+// io.ErrUnexpectedEOF. buf.Format must be set so the expected number of
+// frames is known.
+func ReadFull(r Reader, buf *PCMBuffer) (n int, err error) {
+	return ReadAtLeast(r, buf, buf.NumFrames())
+}
+
+// ReadAtLeast reads from r into buf until it has read at least min frames.
+// It returns the number of frames copied and an error if fewer frames were
+// read. The error is io.EOF only if no frames were read. If an EOF happens
+// after reading fewer than min frames, ReadAtLeast returns
+// io.ErrUnexpectedEOF. If min is greater than the number of frames buf can
+// hold, ReadAtLeast returns io.ErrShortBuffer.
+func ReadAtLeast(r Reader, buf *PCMBuffer, min int) (n int, err error) {
+	total := buf.NumFrames()
+	if total < min {
+		return 0, io.ErrShortBuffer
+	}
+	for n < min && err == nil {
+		var nn int
+		nn, err = r.Read(buf.Slice(n, total))
+		n += nn
+	}
+	if n >= min {
+		err = nil
+	} else if n > 0 && err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+var (
+	_ ReadSeeker  = (*BufferReader)(nil)
+	_ FloatReader = (*BufferReader)(nil)
+)
+
+// BufferReader implements the Reader, FloatReader and Seeker interfaces by
+// reading frames from an in-memory PCMBuffer.
+type BufferReader struct {
+	buf *PCMBuffer
+	pos int // current reading frame
+}
+
+// NewBufferReader returns a new BufferReader reading from buf.
+func NewBufferReader(buf *PCMBuffer) *BufferReader {
+	return &BufferReader{buf: buf}
+}
+
+// Len returns the number of unread frames.
+func (r *BufferReader) Len() int {
+	if r.pos >= r.buf.NumFrames() {
+		return 0
+	}
+	return r.buf.NumFrames() - r.pos
+}
+
+// Read implements the Reader interface. Frames are converted to the data
+// type of buf if it differs from the source buffer's data type. It returns an
+// error wrapping ErrInvalidBuffer if buf is nil or has no data type, and an
+// error wrapping ErrFormatMismatch if its format isn't compatible with the
+// source buffer's, see Format.Compatible.
+func (r *BufferReader) Read(buf *PCMBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, fmt.Errorf("%w: reading into a nil buffer", ErrInvalidBuffer)
+	}
+	if buf.Format == nil && r.buf.Format != nil {
+		buf.Format = r.buf.Format.clone()
+	}
+	if buf.DataType == DataTypeUnknown {
+		return 0, fmt.Errorf("%w: reading into a buffer without a data type", ErrInvalidBuffer)
+	}
+	if n, err = r.next(buf.Format, buf.NumFrames()); n > 0 {
+		copySamples(buf, 0, r.buf.Slice(r.pos, r.pos+n))
+		r.pos += n
+	}
+	return n, err
+}
+
+// ReadFloat implements the FloatReader interface. It returns an error
+// wrapping ErrInvalidBuffer if buf is nil, and an error wrapping
+// ErrFormatMismatch if its format isn't compatible with the source buffer's,
+// see Format.Compatible.
+func (r *BufferReader) ReadFloat(buf *FloatBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, fmt.Errorf("%w: reading into a nil buffer", ErrInvalidBuffer)
+	}
+	if buf.Format == nil && r.buf.Format != nil {
+		buf.Format = r.buf.Format.clone()
+	}
+	if n, err = r.next(buf.Format, buf.NumFrames()); n > 0 {
+		copy(buf.Data, r.buf.Slice(r.pos, r.pos+n).AsF64())
+		r.pos += n
+	}
+	return n, err
+}
+
+// next returns the number of frames to read into a buffer of the given
+// format and number of frames, or io.EOF at the end of the source buffer.
+func (r *BufferReader) next(format *Format, frames int) (int, error) {
+	if frames == 0 {
+		return 0, nil
+	}
+	// a source without a format holds mono frames
+	src := r.buf.Format
+	if src == nil {
+		src = &Format{NumChannels: 1}
+	}
+	if err := src.Compatible(format); err != nil {
+		return 0, err
+	}
+	if r.Len() == 0 {
+		return 0, io.EOF
+	}
+	return min(frames, r.Len()), nil
+}
+
+// Seek implements the Seeker interface.
+func (r *BufferReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = int64(r.pos) + offset
+	case io.SeekEnd:
+		abs = int64(r.buf.NumFrames()) + offset
+	default:
+		return 0, errors.New("audio.BufferReader.Seek: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("audio.BufferReader.Seek: negative position")
+	}
+	r.pos = int(abs)
+	return abs, nil
+}
+
+var (
+	_ Writer      = (*BufferWriter)(nil)
+	_ FloatWriter = (*BufferWriter)(nil)
+)
+
+// BufferWriter implements the Writer and FloatWriter interfaces by appending
+// the written frames to an in-memory PCMBuffer.
+type BufferWriter struct {
+	// Buffer holds the written frames. If its DataType isn't set, it is set
+	// to the data type of the first written buffer, the same goes for its
+	// Format.
+	Buffer *PCMBuffer
+}
+
+// Write implements the Writer interface. Frames are converted to the data
+// type of the destination buffer if it differs from the data type of buf.
+func (w *BufferWriter) Write(buf *PCMBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, fmt.Errorf("%w: writing a nil buffer", ErrInvalidBuffer)
+	}
+	if w.Buffer == nil {
+		w.Buffer = &PCMBuffer{}
+	}
+	if err := w.Buffer.Append(buf); err != nil {
+		return 0, err
+	}
+	return buf.NumFrames(), nil
+}
+
+// WriteFloat implements the FloatWriter interface. Frames are converted to
+// the data type of the destination buffer, which is DataTypeF64 if it isn't
+// set.
+func (w *BufferWriter) WriteFloat(buf *FloatBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, fmt.Errorf("%w: writing a nil buffer", ErrInvalidBuffer)
+	}
+	return w.Write(asPCMBuffer(buf))
+}
+
+// numChannels returns the number of channels described by f, defaulting to
+// one channel like the buffers' NumFrames methods do.
+func numChannels(f *Format) int {
+	if f == nil || f.NumChannels == 0 {
+		return 1
+	}
+	return f.NumChannels
+}
+
+// grow extends the primary store of b by n samples.
+func (b *PCMBuffer) grow(n int) {
+	switch b.DataType {
+	case DataTypeI8:
+		b.I8 = append(b.I8, make([]int8, n)...)
+	case DataTypeI16:
+		b.I16 = append(b.I16, make([]int16, n)...)
+	case DataTypeI32:
+		b.I32 = append(b.I32, make([]int32, n)...)
+	case DataTypeF32:
+		b.F32 = append(b.F32, make([]float32, n)...)
+	case DataTypeF64:
+		b.F64 = append(b.F64, make([]float64, n)...)
+	case DataTypeU8:
+		b.U8 = append(b.U8, make([]uint8, n)...)
+	case DataTypeI24, DataTypeI24BE:
+		b.I24 = append(b.I24, make([]byte, 3*n)...)
+	}
+}
+
+// copySamples copies the samples of src into the primary store of dst
+// starting at the offset sample, converting them if needed. Integer samples
+// are scaled to the bit depth of dst, so 24-bit samples held in int32s stay
+// 24-bit.
+func copySamples(dst *PCMBuffer, offset int, src *PCMBuffer) {
+	bitDepth := dst.intBitDepth()
+	same := src.DataType == dst.DataType && src.intBitDepth() == bitDepth
+	switch dst.DataType {
+	case DataTypeI8:
+		if same {
+			copy(dst.I8[offset:], src.I8)
+		} else {
+			copy(dst.I8[offset:], convertPCM[int8](src, bitDepth))
+		}
+	case DataTypeI16:
+		if same {
+			copy(dst.I16[offset:], src.I16)
+		} else {
+			copy(dst.I16[offset:], convertPCM[int16](src, bitDepth))
+		}
+	case DataTypeI32:
+		if same {
+			copy(dst.I32[offset:], src.I32)
+		} else {
+			copy(dst.I32[offset:], convertPCM[int32](src, bitDepth))
+		}
+	case DataTypeF32:
+		copy(dst.F32[offset:], src.AsF32())
+	case DataTypeF64:
+		copy(dst.F64[offset:], src.AsF64())
+	case DataTypeU8:
+		copy(dst.U8[offset:], src.AsU8())
+	case DataTypeI24:
+		copy(dst.I24[3*offset:], src.AsI24())
+	case DataTypeI24BE:
+		copy(dst.I24[3*offset:], src.AsI24BE())
+	}
+}
@@ -0,0 +1,212 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	src := &PCMBuffer{
+		Format:   FormatStereo44100,
+		F64:      []float64{0.1, -0.1, 0.2, -0.2, 0.3, -0.3, 0.4, -0.4, 0.5, -0.5},
+		DataType: DataTypeF64,
+	}
+	w := &BufferWriter{}
+	n, err := Copy(w, NewBufferReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Errorf("expected 5 frames to be copied, got %d", n)
+	}
+	if !reflect.DeepEqual(w.Buffer.F64, src.F64) {
+		t.Errorf("expected %v got %v", src.F64, w.Buffer.F64)
+	}
+	if w.Buffer.Format.NumChannels != 2 {
+		t.Errorf("expected the format to be copied, got %+v", w.Buffer.Format)
+	}
+}
+
+func TestCopyBuffer(t *testing.T) {
+	src := &PCMBuffer{
+		Format:   FormatMono44100,
+		I16:      []int16{1, 2, 3, 4, 5, 6, 7},
+		DataType: DataTypeI16,
+	}
+	// a small buffer forces several reads/writes
+	buf := &PCMBuffer{I16: make([]int16, 3), DataType: DataTypeI16}
+	w := &BufferWriter{}
+	n, err := CopyBuffer(w, NewBufferReader(src), buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Errorf("expected 7 frames to be copied, got %d", n)
+	}
+	if !reflect.DeepEqual(w.Buffer.I16, src.I16) {
+		t.Errorf("expected %v got %v", src.I16, w.Buffer.I16)
+	}
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(buf *PCMBuffer) (int, error) {
+	return w.n, errors.New("boom")
+}
+
+func TestCopyWriteError(t *testing.T) {
+	src := &PCMBuffer{Format: FormatMono44100, I8: []int8{1, 2, 3}, DataType: DataTypeI8}
+	n, err := Copy(&errWriter{n: 1}, NewBufferReader(src))
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("expected the write error to be returned, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 frame to be reported as written, got %d", n)
+	}
+}
+
+func TestReadFull(t *testing.T) {
+	src := &PCMBuffer{Format: FormatStereo44100, I32: []int32{1, 2, 3, 4, 5, 6}, DataType: DataTypeI32}
+	tests := []struct {
+		name    string
+		frames  int
+		want    int
+		wantErr error
+	}{
+		{"exact", 3, 3, nil},
+		{"partial", 2, 2, nil},
+		{"too many", 4, 3, io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &PCMBuffer{Format: FormatStereo44100, I32: make([]int32, tt.frames*2), DataType: DataTypeI32}
+			n, err := ReadFull(NewBufferReader(src), buf)
+			if n != tt.want || err != tt.wantErr {
+				t.Errorf("ReadFull() = %d, %v, want %d, %v", n, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	r := NewBufferReader(src)
+	r.Seek(0, io.SeekEnd)
+	buf := &PCMBuffer{Format: FormatStereo44100, I32: make([]int32, 2), DataType: DataTypeI32}
+	if n, err := ReadFull(r, buf); n != 0 || err != io.EOF {
+		t.Errorf("ReadFull() at the end of the stream = %d, %v, want 0, EOF", n, err)
+	}
+}
+
+func TestBufferReader_Read24BitInt32(t *testing.T) {
+	r := NewBufferReader(&PCMBuffer{Format: FormatMono44100, I16: []int16{16384, -8192}, DataType: DataTypeI16, SourceBitDepth: 2})
+	buf := &PCMBuffer{Format: FormatMono44100, I32: make([]int32, 2), DataType: DataTypeI32, SourceBitDepth: 3}
+	if _, err := ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{1 << 22, -1 << 21}; !reflect.DeepEqual(buf.I32, want) {
+		t.Errorf("expected %v got %v", want, buf.I32)
+	}
+
+	w := &BufferWriter{Buffer: &PCMBuffer{Format: FormatMono44100, DataType: DataTypeI32, SourceBitDepth: 3}}
+	if _, err := w.Write(&PCMBuffer{Format: FormatMono44100, F64: []float64{0.5}, DataType: DataTypeF64}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{1 << 22}; !reflect.DeepEqual(w.Buffer.I32, want) {
+		t.Errorf("expected %v got %v", want, w.Buffer.I32)
+	}
+}
+
+func TestBufferReader_Seek(t *testing.T) {
+	src := &PCMBuffer{Format: FormatStereo44100, I8: []int8{1, 2, 3, 4, 5, 6, 7, 8}, DataType: DataTypeI8}
+	r := NewBufferReader(src)
+	tests := []struct {
+		offset int64
+		whence int
+		want   int64
+		next   []int8
+	}{
+		{1, io.SeekStart, 1, []int8{3, 4}},
+		{-2, io.SeekCurrent, 0, []int8{1, 2}},
+		{-1, io.SeekEnd, 3, []int8{7, 8}},
+	}
+	for _, tt := range tests {
+		pos, err := r.Seek(tt.offset, tt.whence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos != tt.want {
+			t.Errorf("Seek(%d, %d) = %d, want %d", tt.offset, tt.whence, pos, tt.want)
+		}
+		buf := &PCMBuffer{I8: make([]int8, 2), DataType: DataTypeI8}
+		if _, err := r.Read(buf); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(buf.I8, tt.next) {
+			t.Errorf("expected %v after seeking, got %v", tt.next, buf.I8)
+		}
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Error("expected an error when seeking to a negative position")
+	}
+}
+
+func TestBufferWriter_FormatMismatch(t *testing.T) {
+	w := &BufferWriter{Buffer: &PCMBuffer{Format: FormatMono44100, DataType: DataTypeF64}}
+	buf := &PCMBuffer{Format: FormatStereo44100, F64: []float64{1, 1}, DataType: DataTypeF64}
+	if _, err := w.Write(buf); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+}
+
+func TestBufferReader_ReadFloat(t *testing.T) {
+	src := &PCMBuffer{Format: FormatStereo44100, I16: []int16{16384, -16384, 8192, -8192, 0, 0}, DataType: DataTypeI16}
+	r := NewBufferReader(src)
+	w := &BufferWriter{}
+	buf := &FloatBuffer{Data: make([]float64, 4)}
+	for {
+		n, err := r.ReadFloat(buf)
+		if n > 0 {
+			if _, err := w.WriteFloat(buf.Slice(0, n)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []float64{0.5, -0.5, 0.25, -0.25, 0, 0}; w.Buffer.DataType != DataTypeF64 || !reflect.DeepEqual(w.Buffer.F64, want) {
+		t.Errorf("expected %v got %+v", want, w.Buffer)
+	}
+	if w.Buffer.Format.NumChannels != 2 {
+		t.Errorf("expected the format to be copied, got %+v", w.Buffer.Format)
+	}
+}
+
+func TestBufferReader_Errors(t *testing.T) {
+	src := &PCMBuffer{Format: FormatStereo44100, I8: []int8{1, 2}, DataType: DataTypeI8}
+	tests := []struct {
+		name string
+		read func(r *BufferReader) error
+		err  error
+	}{
+		{"nil buffer", func(r *BufferReader) error { _, err := r.Read(nil); return err }, ErrInvalidBuffer},
+		{"nil float buffer", func(r *BufferReader) error { _, err := r.ReadFloat(nil); return err }, ErrInvalidBuffer},
+		{"no data type", func(r *BufferReader) error { _, err := r.Read(&PCMBuffer{}); return err }, ErrInvalidBuffer},
+		{"channels", func(r *BufferReader) error {
+			_, err := r.Read(&PCMBuffer{Format: FormatMono44100, I8: make([]int8, 2), DataType: DataTypeI8})
+			return err
+		}, ErrFormatMismatch},
+		{"sample rate", func(r *BufferReader) error {
+			_, err := r.ReadFloat(&FloatBuffer{Format: FormatStereo48000, Data: make([]float64, 2)})
+			return err
+		}, ErrFormatMismatch},
+	}
+	for _, tt := range tests {
+		if err := tt.read(NewBufferReader(src)); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
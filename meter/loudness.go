@@ -0,0 +1,293 @@
+package meter
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-audio/audio"
+)
+
+const (
+	// absoluteGate is the loudness, in LUFS, under which blocks are ignored.
+	absoluteGate = -70
+	// relativeGate is the gate, in LU relative to the ungated loudness,
+	// used for the integrated loudness.
+	relativeGate = -10
+	// rangeGate is the relative gate, in LU, used for the loudness range.
+	rangeGate = -20
+	// momentaryBlocks and shortTermBlocks are the number of 100 ms
+	// sub-blocks of the momentary (400 ms) and short-term (3 s) windows.
+	momentaryBlocks = 4
+	shortTermBlocks = 30
+)
+
+// LoudnessMeter measures the loudness of a stream as specified by ITU-R
+// BS.1770-4 and EBU R128. Loudness values are expressed in LUFS, -Inf when
+// not enough audio has been measured.
+type LoudnessMeter struct {
+	weights []float64
+	filters [][2]biquad
+	// step is the number of frames of a 100 ms sub-block.
+	step int
+	// frames is the number of frames of the current sub-block and energy
+	// the sum of its weighted squared samples.
+	frames int
+	energy float64
+	// subBlocks holds the energies of the last sub-blocks and count the
+	// number of completed sub-blocks.
+	subBlocks [shortTermBlocks]float64
+	count     int
+	// blocks holds the mean energy of every 400 ms gating block and
+	// shortTerm of every 3 s window, sampled every 100 ms.
+	blocks    []float64
+	shortTerm []float64
+}
+
+// NewLoudnessMeter returns a meter for a stream of the given format. The
+// channels are weighted according to the format's layout (the default layout
+// of its number of channels if not set): LFE channels are ignored and
+// surround channels weighted by 1.41. It returns an error wrapping
+// audio.ErrInvalidSampleRate or audio.ErrInvalidNumChannels if the format
+// isn't valid.
+func NewLoudnessMeter(format *audio.Format) (*LoudnessMeter, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	nc := format.NumChannels
+	m := &LoudnessMeter{
+		weights: channelWeights(format),
+		filters: make([][2]biquad, nc),
+		step:    int(math.Round(float64(format.SampleRate) / 10)),
+	}
+	pre, rlb := kWeighting(float64(format.SampleRate))
+	for c := range m.filters {
+		m.filters[c] = [2]biquad{pre, rlb}
+	}
+	return m, nil
+}
+
+// Process measures the frames of buf. It returns an error wrapping
+// audio.ErrFormatMismatch if buf doesn't have the number of channels of the
+// meter.
+func (m *LoudnessMeter) Process(buf audio.Buffer) error {
+	data, err := samples(buf, len(m.weights))
+	if err != nil {
+		return err
+	}
+	nc := len(m.weights)
+	for f := 0; f < len(data); f += nc {
+		for c, w := range m.weights {
+			y := m.filters[c][0].process(data[f+c])
+			y = m.filters[c][1].process(y)
+			m.energy += w * y * y
+		}
+		m.frames++
+		if m.frames == m.step {
+			m.completeSubBlock()
+		}
+	}
+	return nil
+}
+
+// completeSubBlock records the current sub-block and the gating blocks
+// ending with it.
+func (m *LoudnessMeter) completeSubBlock() {
+	m.subBlocks[m.count%shortTermBlocks] = m.energy
+	m.count++
+	m.frames, m.energy = 0, 0
+	if m.count >= momentaryBlocks {
+		m.blocks = append(m.blocks, m.window(momentaryBlocks))
+	}
+	if m.count >= shortTermBlocks {
+		m.shortTerm = append(m.shortTerm, m.window(shortTermBlocks))
+	}
+}
+
+// window returns the mean energy of the last n sub-blocks.
+func (m *LoudnessMeter) window(n int) float64 {
+	var sum float64
+	for i := 1; i <= n; i++ {
+		sum += m.subBlocks[(m.count-i)%shortTermBlocks]
+	}
+	return sum / float64(n*m.step)
+}
+
+// Momentary returns the loudness of the last 400 ms.
+func (m *LoudnessMeter) Momentary() float64 {
+	if m.count < momentaryBlocks {
+		return math.Inf(-1)
+	}
+	return loudness(m.window(momentaryBlocks))
+}
+
+// ShortTerm returns the loudness of the last 3 seconds.
+func (m *LoudnessMeter) ShortTerm() float64 {
+	if m.count < shortTermBlocks {
+		return math.Inf(-1)
+	}
+	return loudness(m.window(shortTermBlocks))
+}
+
+// MaxMomentary returns the highest momentary loudness measured so far.
+func (m *LoudnessMeter) MaxMomentary() float64 {
+	return loudness(maxValue(m.blocks))
+}
+
+// MaxShortTerm returns the highest short-term loudness measured so far.
+func (m *LoudnessMeter) MaxShortTerm() float64 {
+	return loudness(maxValue(m.shortTerm))
+}
+
+// Integrated returns the gated loudness of the whole stream.
+func (m *LoudnessMeter) Integrated() float64 {
+	gated := gate(m.blocks, relativeGate)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	return loudness(mean(gated))
+}
+
+// Range returns the loudness range (LRA) of the stream in LU, as specified
+// by EBU Tech 3342: the spread between the 10th and the 95th percentiles of
+// the gated short-term loudness distribution.
+func (m *LoudnessMeter) Range() float64 {
+	gated := gate(m.shortTerm, rangeGate)
+	if len(gated) == 0 {
+		return 0
+	}
+	sort.Float64s(gated)
+	percentile := func(p float64) float64 {
+		return loudness(gated[int(math.Round(float64(len(gated)-1)*p))])
+	}
+	return percentile(0.95) - percentile(0.10)
+}
+
+// Reset clears the measures and the filter states.
+func (m *LoudnessMeter) Reset() {
+	for c := range m.filters {
+		m.filters[c][0].reset()
+		m.filters[c][1].reset()
+	}
+	m.frames, m.energy, m.count = 0, 0, 0
+	m.blocks, m.shortTerm = m.blocks[:0], m.shortTerm[:0]
+}
+
+// gate returns the energies above the absolute gate and above the relative
+// gate (in LU) of their mean loudness.
+func gate(energies []float64, relative float64) []float64 {
+	var gated []float64
+	abs := energy(absoluteGate)
+	for _, z := range energies {
+		if z > abs {
+			gated = append(gated, z)
+		}
+	}
+	if len(gated) == 0 {
+		return nil
+	}
+	rel := energy(loudness(mean(gated)) + relative)
+	out := gated[:0]
+	for _, z := range gated {
+		if z > rel {
+			out = append(out, z)
+		}
+	}
+	return out
+}
+
+// loudness converts a weighted mean energy to LUFS.
+func loudness(z float64) float64 {
+	return -0.691 + 10*math.Log10(z)
+}
+
+// energy converts a loudness in LUFS to a weighted mean energy.
+func energy(lufs float64) float64 {
+	return math.Pow(10, (lufs+0.691)/10)
+}
+
+// mean returns the mean of the values.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// maxValue returns the highest of the values, 0 if there are none.
+func maxValue(values []float64) float64 {
+	var max float64
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	return max
+}
+
+// channelWeights returns the BS.1770 weight of each channel of the format.
+func channelWeights(format *audio.Format) []float64 {
+	layout := format.Layout
+	if layout.IsZero() {
+		layout = audio.DefaultLayout(format.NumChannels)
+	}
+	weights := make([]float64, format.NumChannels)
+	positions := layout.Positions()
+	for c := range weights {
+		weights[c] = 1
+		if c >= len(positions) {
+			continue
+		}
+		switch positions[c] {
+		case audio.LowFrequency:
+			weights[c] = 0
+		case audio.BackLeft, audio.BackRight, audio.SideLeft, audio.SideRight:
+			weights[c] = 1.41
+		}
+	}
+	return weights
+}
+
+// biquad is a second order IIR filter in transposed direct form II.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+func (f *biquad) reset() {
+	f.z1, f.z2 = 0, 0
+}
+
+// kWeighting returns the two stages of the BS.1770 K-weighting filter, a
+// high shelf modeling the head and a high-pass filter (RLB), designed for
+// the sample rate rate.
+func kWeighting(rate float64) (pre, rlb biquad) {
+	// pre-filter
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / rate)
+	vh := audio.DBToGain(gain)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	pre = biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	// revised low-frequency B-curve
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / rate)
+	a0 = 1 + k/q + k*k
+	rlb = biquad{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return pre, rlb
+}
@@ -0,0 +1,106 @@
+/*
+Package meter measures the levels and the loudness of audio buffers.
+
+LevelMeter reports the sample peak, RMS level, crest factor and DC offset of
+each channel, LoudnessMeter the ITU-R BS.1770 / EBU R128 momentary,
+short-term and integrated loudness as well as the loudness range, and
+TruePeakMeter the oversampled true peak. The meters keep their state between
+calls to Process so they can measure a stream buffer after buffer, Analyze
+runs all of them on a whole buffer.
+*/
+package meter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-audio/audio"
+)
+
+// Levels holds the level measures of a channel, samples being normalized to
+// the [-1, 1] range.
+type Levels struct {
+	// Peak is the highest absolute sample value.
+	Peak float64
+	// RMS is the root mean square of the samples.
+	RMS float64
+	// DC is the mean of the samples.
+	DC float64
+	// Samples is the number of measured samples.
+	Samples int
+}
+
+// Crest returns the crest factor, the ratio of the peak to the RMS level, or
+// 0 for silence. Use audio.GainToDB to express it in decibels.
+func (l Levels) Crest() float64 {
+	if l.RMS == 0 {
+		return 0
+	}
+	return l.Peak / l.RMS
+}
+
+// LevelMeter measures the levels of each channel of a stream.
+type LevelMeter struct {
+	peak, sum, sumSquares []float64
+	samples               int
+}
+
+// NewLevelMeter returns a meter for a stream of numChannels channels.
+func NewLevelMeter(numChannels int) *LevelMeter {
+	return &LevelMeter{
+		peak:       make([]float64, numChannels),
+		sum:        make([]float64, numChannels),
+		sumSquares: make([]float64, numChannels),
+	}
+}
+
+// Process measures the frames of buf. It returns an error wrapping
+// audio.ErrFormatMismatch if buf doesn't have the number of channels of the
+// meter.
+func (m *LevelMeter) Process(buf audio.Buffer) error {
+	data, err := samples(buf, len(m.peak))
+	if err != nil {
+		return err
+	}
+	nc := len(m.peak)
+	for i, s := range data {
+		c := i % nc
+		m.peak[c] = math.Max(m.peak[c], math.Abs(s))
+		m.sum[c] += s
+		m.sumSquares[c] += s * s
+	}
+	m.samples += len(data) / nc
+	return nil
+}
+
+// Levels returns the levels of each channel measured so far.
+func (m *LevelMeter) Levels() []Levels {
+	levels := make([]Levels, len(m.peak))
+	for c := range levels {
+		levels[c] = Levels{Peak: m.peak[c], Samples: m.samples}
+		if m.samples > 0 {
+			levels[c].RMS = math.Sqrt(m.sumSquares[c] / float64(m.samples))
+			levels[c].DC = m.sum[c] / float64(m.samples)
+		}
+	}
+	return levels
+}
+
+// Reset clears the measures.
+func (m *LevelMeter) Reset() {
+	*m = *NewLevelMeter(len(m.peak))
+}
+
+// samples returns the whole frames of buf as floats, checking that buf has
+// numChannels channels.
+func samples(buf audio.Buffer, numChannels int) ([]float64, error) {
+	fb := buf.AsFloatBuffer()
+	nc := 1
+	if fb.Format != nil && fb.Format.NumChannels > 0 {
+		nc = fb.Format.NumChannels
+	}
+	if nc != numChannels {
+		return nil, fmt.Errorf("%w: measuring %d channels with a %d channel meter", audio.ErrFormatMismatch, nc, numChannels)
+	}
+	return fb.Data[:len(fb.Data)/nc*nc], nil
+}
@@ -0,0 +1,149 @@
+package meter
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+// sine returns a buffer holding a sine of the given peak amplitude on every
+// channel.
+func sine(format *audio.Format, freq, amplitude, phase float64, seconds float64) *audio.FloatBuffer {
+	frames := int(seconds * float64(format.SampleRate))
+	buf := &audio.FloatBuffer{Format: format, Data: make([]float64, frames*format.NumChannels)}
+	for f := 0; f < frames; f++ {
+		s := amplitude * math.Sin(2*math.Pi*freq*float64(f)/float64(format.SampleRate)+phase)
+		for c := 0; c < format.NumChannels; c++ {
+			buf.Data[f*format.NumChannels+c] = s
+		}
+	}
+	return buf
+}
+
+func TestLevelMeter(t *testing.T) {
+	m := NewLevelMeter(2)
+	bufs := []audio.Buffer{
+		&audio.FloatBuffer{Format: audio.FormatStereo44100, Data: []float64{0.5, 1, -0.5, 1}},
+		&audio.IntBuffer{Format: audio.FormatStereo44100, Data: []int{16384, 32767, -16384, 32767}, SourceBitDepth: 16},
+	}
+	for _, buf := range bufs {
+		if err := m.Process(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	levels := m.Levels()
+	if l := levels[0]; l.Peak != 0.5 || l.RMS != 0.5 || l.DC != 0 || l.Crest() != 1 || l.Samples != 4 {
+		t.Errorf("unexpected left levels %+v", l)
+	}
+	if l := levels[1]; math.Abs(l.DC-1) > 1e-4 || math.Abs(l.RMS-1) > 1e-4 {
+		t.Errorf("unexpected right levels %+v", l)
+	}
+	if err := m.Process(&audio.FloatBuffer{Format: audio.FormatMono44100}); err == nil {
+		t.Error("expected an error measuring a mono buffer")
+	}
+}
+
+func TestLoudnessMeter(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: 48000}
+	tests := []struct {
+		name      string
+		format    *audio.Format
+		amplitude float64
+		want      float64
+	}{
+		// EBU Tech 3341 test cases 1 and 2
+		{"stereo -23 dBFS", format, audio.DBToGain(-23), -23},
+		{"stereo -33 dBFS", format, math.Pow(10, -33.0/20), -33},
+		{"mono -23 dBFS", &audio.Format{NumChannels: 1, SampleRate: 44100}, audio.DBToGain(-23), -26.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewLoudnessMeter(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := sine(tt.format, 1000, tt.amplitude, 0, 20)
+			// stream the buffer in chunks of 1000 frames
+			for _, chunk := range buf.Split(1000) {
+				if err := m.Process(chunk); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for name, got := range map[string]float64{
+				"integrated": m.Integrated(), "momentary": m.Momentary(), "short-term": m.ShortTerm(),
+				"max momentary": m.MaxMomentary(), "max short-term": m.MaxShortTerm(),
+			} {
+				if math.Abs(got-tt.want) > 0.1 {
+					t.Errorf("expected a %s loudness of %v LUFS, got %v", name, tt.want, got)
+				}
+			}
+			if lra := m.Range(); lra > 0.1 {
+				t.Errorf("expected no loudness range, got %v LU", lra)
+			}
+		})
+	}
+}
+
+func TestLoudnessMeter_Range(t *testing.T) {
+	// EBU Tech 3342 test case 1
+	format := &audio.Format{NumChannels: 2, SampleRate: 48000}
+	m, err := NewLoudnessMeter(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dbfs := range []float64{-20, -30, -20} {
+		if err := m.Process(sine(format, 1000, math.Pow(10, dbfs/20), 0, 20)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lra := m.Range(); math.Abs(lra-10) > 1 {
+		t.Errorf("expected a loudness range of 10 LU, got %v", lra)
+	}
+	m.Reset()
+	if got := m.Integrated(); !math.IsInf(got, -1) {
+		t.Errorf("expected -Inf after a reset, got %v", got)
+	}
+}
+
+func TestTruePeakMeter(t *testing.T) {
+	format := &audio.Format{NumChannels: 1, SampleRate: 48000}
+	m, err := NewTruePeakMeter(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a sine at a quarter of the sample rate sampled at 45° only has
+	// samples at 0.707 of its peak
+	buf := sine(format, 12000, 1, math.Pi/4, 0.1)
+	if err := m.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+	if tp := audio.GainToDB(m.TruePeak()); tp < -0.5 || tp > 0.5 {
+		t.Errorf("expected a true peak of 0 dBTP, got %v", tp)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: 48000}
+	r, err := Analyze(sine(format, 1000, audio.DBToGain(-23), 0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EBUR128.Check(r); err != nil {
+		t.Errorf("expected the sine to comply, got %v", err)
+	}
+	if err := ATSCA85.Check(r); err != nil {
+		t.Errorf("expected the sine to comply, got %v", err)
+	}
+	r, err = Analyze(sine(format, 1000, 1, 0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EBUR128.Check(r); err == nil {
+		t.Error("expected a full scale sine not to comply")
+	}
+	if _, err := Analyze(nil); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package meter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/go-audio/audio"
+)
+
+// ErrNonCompliant is returned when a report doesn't meet a loudness spec.
+var ErrNonCompliant = errors.New("loudness doesn't meet the spec")
+
+// Report gathers the measures of a buffer.
+type Report struct {
+	// Channels holds the levels of each channel.
+	Channels []Levels
+	// TruePeaks holds the linear true peak of each channel.
+	TruePeaks []float64
+	// Integrated is the integrated loudness, in LUFS.
+	Integrated float64
+	// Range is the loudness range, in LU.
+	Range float64
+	// MaxMomentary and MaxShortTerm are the highest momentary and
+	// short-term loudness, in LUFS.
+	MaxMomentary, MaxShortTerm float64
+}
+
+// TruePeak returns the highest true peak of all the channels, in dBTP.
+func (r *Report) TruePeak() float64 {
+	return audio.GainToDB(maxValue(r.TruePeaks))
+}
+
+// Analyze measures the levels and the loudness of buf. It returns an error
+// wrapping audio.ErrInvalidBuffer if buf is nil.
+func Analyze(buf audio.Buffer) (*Report, error) {
+	if buf == nil {
+		return nil, fmt.Errorf("%w: analyzing a nil buffer", audio.ErrInvalidBuffer)
+	}
+	format := buf.PCMFormat()
+	loudness, err := NewLoudnessMeter(format)
+	if err != nil {
+		return nil, err
+	}
+	truePeak, err := NewTruePeakMeter(format)
+	if err != nil {
+		return nil, err
+	}
+	levels := NewLevelMeter(format.NumChannels)
+	for _, p := range []interface{ Process(audio.Buffer) error }{levels, loudness, truePeak} {
+		if err := p.Process(buf); err != nil {
+			return nil, err
+		}
+	}
+	return &Report{
+		Channels:     levels.Levels(),
+		TruePeaks:    truePeak.TruePeaks(),
+		Integrated:   loudness.Integrated(),
+		Range:        loudness.Range(),
+		MaxMomentary: loudness.MaxMomentary(),
+		MaxShortTerm: loudness.MaxShortTerm(),
+	}, nil
+}
+
+// Spec is a loudness delivery specification.
+type Spec struct {
+	// Name is the name of the specification.
+	Name string
+	// Integrated is the target integrated loudness, in LUFS.
+	Integrated float64
+	// Tolerance is the accepted deviation from the target, in LU.
+	Tolerance float64
+	// MaxTruePeak is the maximum true peak, in dBTP.
+	MaxTruePeak float64
+}
+
+// Common loudness specifications.
+var (
+	// EBUR128 is the EBU R128 recommendation for broadcast programmes.
+	EBUR128 = Spec{Name: "EBU R128", Integrated: -23, Tolerance: 0.5, MaxTruePeak: -1}
+	// ATSCA85 is the ATSC A/85 recommended practice.
+	ATSCA85 = Spec{Name: "ATSC A/85", Integrated: -24, Tolerance: 2, MaxTruePeak: -2}
+)
+
+// Check returns an error wrapping ErrNonCompliant if the integrated loudness
+// or the true peak of the report don't meet the spec.
+func (s Spec) Check(r *Report) error {
+	if math.Abs(r.Integrated-s.Integrated) > s.Tolerance || math.IsInf(r.Integrated, 0) {
+		return fmt.Errorf("%w: %s integrated loudness of %.1f LUFS, expected %.1f ±%.1f LUFS", ErrNonCompliant, s.Name, r.Integrated, s.Integrated, s.Tolerance)
+	}
+	if tp := r.TruePeak(); tp > s.MaxTruePeak {
+		return fmt.Errorf("%w: %s true peak of %.1f dBTP, expected at most %.1f dBTP", ErrNonCompliant, s.Name, tp, s.MaxTruePeak)
+	}
+	return nil
+}
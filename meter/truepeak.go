@@ -0,0 +1,130 @@
+package meter
+
+import (
+	"math"
+
+	"github.com/go-audio/audio"
+)
+
+// truePeakTaps is the number of taps of each phase of the oversampling
+// filter.
+const truePeakTaps = 12
+
+// TruePeakMeter measures the true peak of each channel of a stream, that is
+// to say the peak of the reconstructed analog signal, as specified by ITU-R
+// BS.1770-4: streams under 96 kHz are oversampled 4 times, streams under
+// 192 kHz twice.
+type TruePeakMeter struct {
+	// phases holds the coefficients of each phase of the polyphase
+	// interpolation filter.
+	phases [][]float64
+	// history holds the last input samples of each channel, the most
+	// recent last.
+	history [][]float64
+	peak    []float64
+}
+
+// NewTruePeakMeter returns a meter for a stream of the given format. It
+// returns an error wrapping audio.ErrInvalidSampleRate or
+// audio.ErrInvalidNumChannels if the format isn't valid.
+func NewTruePeakMeter(format *audio.Format) (*TruePeakMeter, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	factor := 1
+	switch {
+	case format.SampleRate < 96000:
+		factor = 4
+	case format.SampleRate < 192000:
+		factor = 2
+	}
+	m := &TruePeakMeter{
+		phases:  interpolationFilter(factor),
+		history: make([][]float64, format.NumChannels),
+		peak:    make([]float64, format.NumChannels),
+	}
+	for c := range m.history {
+		m.history[c] = make([]float64, truePeakTaps)
+	}
+	return m, nil
+}
+
+// Process measures the frames of buf. It returns an error wrapping
+// audio.ErrFormatMismatch if buf doesn't have the number of channels of the
+// meter.
+func (m *TruePeakMeter) Process(buf audio.Buffer) error {
+	data, err := samples(buf, len(m.peak))
+	if err != nil {
+		return err
+	}
+	nc := len(m.peak)
+	for i, s := range data {
+		c := i % nc
+		h := m.history[c]
+		copy(h, h[1:])
+		h[len(h)-1] = s
+		peak := math.Abs(s)
+		for _, phase := range m.phases {
+			var y float64
+			for j, coef := range phase {
+				y += coef * h[len(h)-1-j]
+			}
+			peak = math.Max(peak, math.Abs(y))
+		}
+		m.peak[c] = math.Max(m.peak[c], peak)
+	}
+	return nil
+}
+
+// TruePeaks returns the true peak of each channel measured so far, as a
+// linear level. Use audio.GainToDB to express it in dBTP.
+func (m *TruePeakMeter) TruePeaks() []float64 {
+	peaks := make([]float64, len(m.peak))
+	copy(peaks, m.peak)
+	return peaks
+}
+
+// TruePeak returns the highest true peak of all the channels.
+func (m *TruePeakMeter) TruePeak() float64 {
+	return maxValue(m.peak)
+}
+
+// Reset clears the measures and the filter states.
+func (m *TruePeakMeter) Reset() {
+	for c := range m.history {
+		clear(m.history[c])
+		m.peak[c] = 0
+	}
+}
+
+// interpolationFilter returns the phases of a Blackman windowed-sinc
+// low-pass filter interpolating factor samples between each input sample,
+// each phase being normalized to a unity DC gain.
+func interpolationFilter(factor int) [][]float64 {
+	n := factor * truePeakTaps
+	phases := make([][]float64, factor)
+	for p := range phases {
+		phases[p] = make([]float64, truePeakTaps)
+	}
+	center := float64(n-1) / 2
+	for k := 0; k < n; k++ {
+		x := (float64(k) - center) / float64(factor)
+		h := 1.0
+		if x != 0 {
+			h = math.Sin(math.Pi*x) / (math.Pi * x)
+		}
+		t := 2 * math.Pi * float64(k) / float64(n-1)
+		h *= 0.42 - 0.5*math.Cos(t) + 0.08*math.Cos(2*t)
+		phases[k%factor][k/factor] = h
+	}
+	for _, phase := range phases {
+		var sum float64
+		for _, c := range phase {
+			sum += c
+		}
+		for i := range phase {
+			phase[i] /= sum
+		}
+	}
+	return phases
+}
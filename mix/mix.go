@@ -0,0 +1,269 @@
+/*
+Package mix sums several audio buffers into one, for instance a music bed
+and a voice-over.
+
+Each source is converted to the format of the destination: samples are
+normalized to floats, channels are mapped (panning mono sources over stereo
+destinations) and, if the Mixer allows it, sample rates are converted. The
+sum is written back into the destination buffer, integer samples being
+saturated to their bit depth.
+*/
+package mix
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/resample"
+)
+
+// softClipThreshold is the level from which SoftClip starts compressing
+// the samples.
+const softClipThreshold = 0.8
+
+// ClipMode describes how a Mixer handles sums exceeding the full scale.
+type ClipMode uint8
+
+const (
+	// NoClip keeps the sum as is. Float destinations may hold samples out
+	// of the [-1, 1] range while integer destinations saturate.
+	NoClip ClipMode = iota
+	// HardClip clips the samples of the sum to the [-1, 1] range.
+	HardClip
+	// SoftClip leaves the samples under 0.8 untouched and smoothly
+	// compresses the louder ones so they never reach the full scale.
+	SoftClip
+	// Limit scales the whole sum down when its peak exceeds the full scale,
+	// avoiding any distortion at the cost of a lower level.
+	Limit
+)
+
+// Source is a buffer to mix.
+type Source struct {
+	// Buffer holds the frames to mix.
+	Buffer audio.Buffer
+	// GainDB is the gain applied to the source, in decibels.
+	GainDB float64
+	// Offset is the frame of the destination at which the source starts.
+	// Frames of sources starting before the destination (negative offsets)
+	// are dropped.
+	Offset int
+	// Pan goes from -1 (left) to 1 (right), 0 being centered. It only
+	// applies to mono and stereo sources mixed into stereo destinations,
+	// stereo sources being balanced.
+	Pan float64
+}
+
+// Mixer sums sources into a destination buffer. The zero value mixes without
+// clipping and refuses sources using a different sample rate.
+type Mixer struct {
+	// Clip is the way sums exceeding the full scale are handled.
+	Clip ClipMode
+	// PanLaw is the pan law used for mono sources mixed into stereo
+	// destinations.
+	PanLaw audio.PanLaw
+	// Resample allows mixing sources using a different sample rate than the
+	// destination, converting them with the Quality resampler.
+	Resample bool
+	// Quality is the quality of the resampler, see Resample.
+	Quality resample.Quality
+}
+
+// Mix adds the sources to dst with a zero Mixer, see Mixer.Mix.
+func Mix(dst audio.Buffer, srcs ...Source) error {
+	var m Mixer
+	return m.Mix(dst, srcs...)
+}
+
+// Mix adds the sources to the existing content of dst, extending dst if a
+// source ends after it. Sources with a different number of channels are
+// converted with the built-in matrices of audio.MixMatrix.
+//
+// It returns an error wrapping audio.ErrFormatMismatch if a source uses a
+// different sample rate and m doesn't resample, or if its channels can't be
+// mapped to the channels of dst, and an error wrapping audio.ErrInvalidBuffer
+// if dst or the buffer of a source is nil. dst is left untouched on errors.
+func (m *Mixer) Mix(dst audio.Buffer, srcs ...Source) error {
+	if dst == nil {
+		return fmt.Errorf("%w: mixing into a nil buffer", audio.ErrInvalidBuffer)
+	}
+	format := dst.PCMFormat()
+	if format == nil {
+		return fmt.Errorf("%w: mixing into a buffer without a format", audio.ErrNilFormat)
+	}
+	nc := numChannels(dst)
+	if nc == 0 {
+		return fmt.Errorf("%w: mixing into a planar buffer without channels", audio.ErrInvalidBuffer)
+	}
+	for i, s := range srcs {
+		if s.Buffer == nil {
+			return fmt.Errorf("%w: source %d has no buffer", audio.ErrInvalidBuffer, i)
+		}
+	}
+	frames := dst.NumFrames()
+	sums := make([][]float64, len(srcs))
+	for i, s := range srcs {
+		data, err := m.convert(s, format, nc)
+		if err != nil {
+			return err
+		}
+		sums[i] = data
+		frames = max(frames, s.Offset+len(data)/nc)
+	}
+
+	acc := make([]float64, frames*nc)
+	copy(acc, dst.AsFloatBuffer().Data)
+	for i, s := range srcs {
+		data := sums[i]
+		if s.Offset < 0 {
+			data = data[min(len(data), -s.Offset*nc):]
+		}
+		out := acc[max(0, s.Offset)*nc:]
+		for j, v := range data {
+			out[j] += v
+		}
+	}
+	m.clip(acc)
+
+	if err := extend(dst, frames-dst.NumFrames()); err != nil {
+		return err
+	}
+	return audio.TransformFloat(dst, func(samples []float64, frame int) {
+		copy(samples, acc[frame*nc:])
+	})
+}
+
+// convert returns the samples of s converted to the format of the
+// destination, its gain and pan applied.
+func (m *Mixer) convert(s Source, format *audio.Format, nc int) ([]float64, error) {
+	fb := s.Buffer.AsFloatBuffer()
+	from := fb.Format
+	if from == nil {
+		from = format
+	}
+	if from.SampleRate != 0 && format.SampleRate != 0 && from.SampleRate != format.SampleRate {
+		if !m.Resample {
+			return nil, fmt.Errorf("%w: mixing %d Hz into %d Hz", audio.ErrFormatMismatch, from.SampleRate, format.SampleRate)
+		}
+		var err error
+		if fb, err = resample.Resample(fb, format.SampleRate, m.Quality); err != nil {
+			return nil, err
+		}
+	}
+	sc := numChannels(fb)
+	matrix, err := m.matrix(from, sc, format, nc, s.Pan)
+	if err != nil {
+		return nil, err
+	}
+	gain := audio.DBToGain(s.GainDB)
+	frames := len(fb.Data) / sc
+	out := make([]float64, frames*nc)
+	for f := 0; f < frames; f++ {
+		in := fb.Data[f*sc : (f+1)*sc]
+		for o, row := range matrix {
+			var v float64
+			for i, g := range row {
+				v += g * in[i]
+			}
+			out[f*nc+o] = v * gain
+		}
+	}
+	return out, nil
+}
+
+// matrix returns the matrix mapping the sc channels of a source to the nc
+// channels of the destination.
+func (m *Mixer) matrix(from *audio.Format, sc int, to *audio.Format, nc int, pan float64) ([][]float64, error) {
+	switch {
+	case sc == 1 && nc == 2:
+		return audio.PanMatrix(pan, m.PanLaw), nil
+	case sc == 2 && nc == 2:
+		pan = math.Max(-1, math.Min(1, pan))
+		return [][]float64{{math.Min(1, 1-pan), 0}, {0, math.Min(1, 1+pan)}}, nil
+	case sc == nc:
+		matrix := make([][]float64, nc)
+		for i := range matrix {
+			matrix[i] = make([]float64, nc)
+			matrix[i][i] = 1
+		}
+		return matrix, nil
+	}
+	matrix, err := audio.MixMatrix(layout(from, sc), layout(to, nc))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", audio.ErrFormatMismatch, err)
+	}
+	return matrix, nil
+}
+
+// clip applies the clip mode of m to the samples.
+func (m *Mixer) clip(samples []float64) {
+	switch m.Clip {
+	case HardClip:
+		for i, s := range samples {
+			samples[i] = math.Max(-1, math.Min(1, s))
+		}
+	case SoftClip:
+		for i, s := range samples {
+			if a := math.Abs(s); a > softClipThreshold {
+				a = softClipThreshold + (1-softClipThreshold)*math.Tanh((a-softClipThreshold)/(1-softClipThreshold))
+				samples[i] = math.Copysign(a, s)
+			}
+		}
+	case Limit:
+		var peak float64
+		for _, s := range samples {
+			peak = math.Max(peak, math.Abs(s))
+		}
+		if peak > 1 {
+			for i := range samples {
+				samples[i] /= peak
+			}
+		}
+	}
+}
+
+// extend appends n silent frames to b.
+func extend(b audio.Buffer, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	nc := numChannels(b)
+	switch b := b.(type) {
+	case *audio.FloatBuffer:
+		b.Data = append(b.Data, make([]float64, n*nc)...)
+	case *audio.Float32Buffer:
+		b.Data = append(b.Data, make([]float32, n*nc)...)
+	case *audio.IntBuffer:
+		b.Data = append(b.Data, make([]int, n*nc)...)
+	case *audio.PlanarFloatBuffer:
+		for i := range b.Data {
+			b.Data[i] = append(b.Data[i], make([]float64, n)...)
+		}
+	case *audio.PCMBuffer:
+		return b.Append(&audio.PCMBuffer{Format: b.Format, F64: make([]float64, n*nc), DataType: audio.DataTypeF64})
+	default:
+		return fmt.Errorf("%w: can't extend %T", audio.ErrInvalidBuffer, b)
+	}
+	return nil
+}
+
+// numChannels returns the number of interleaved channels of b.
+func numChannels(b audio.Buffer) int {
+	if pb, ok := b.(*audio.PlanarFloatBuffer); ok {
+		return len(pb.Data)
+	}
+	if f := b.PCMFormat(); f != nil && f.NumChannels > 0 {
+		return f.NumChannels
+	}
+	return 1
+}
+
+// layout returns the layout of the format or the default layout of nc
+// channels.
+func layout(f *audio.Format, nc int) audio.ChannelLayout {
+	if f == nil || f.Layout.IsZero() {
+		return audio.DefaultLayout(nc)
+	}
+	return f.Layout
+}
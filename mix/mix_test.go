@@ -0,0 +1,125 @@
+package mix
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/resample"
+)
+
+func approxSlice(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMix(t *testing.T) {
+	bed := &audio.FloatBuffer{Format: audio.FormatStereo44100, Data: []float64{0.1, 0.2, 0.1, 0.2}}
+	voice := &audio.PCMBuffer{Format: audio.FormatMono44100, I16: []int16{16384, -16384}, DataType: audio.DataTypeI16}
+	err := Mix(bed,
+		Source{Buffer: voice, Offset: 1, Pan: -1},
+		Source{Buffer: &audio.IntBuffer{Format: audio.FormatStereo44100, Data: []int{16384, 16384}, SourceBitDepth: 16}, GainDB: -6.020599913279624, Pan: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the voice extends the bed by a frame, panned to the left
+	want := []float64{0.1, 0.45, 0.6, 0.2, -0.5, 0}
+	if !approxSlice(bed.Data, want) {
+		t.Errorf("expected %v, got %v", want, bed.Data)
+	}
+}
+
+func TestMix_IntDestination(t *testing.T) {
+	dst := &audio.PCMBuffer{Format: audio.FormatMono44100, I16: []int16{30000, 100}, DataType: audio.DataTypeI16}
+	src := &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{0.5, 0.5}}
+	if err := Mix(dst, Source{Buffer: src}); err != nil {
+		t.Fatal(err)
+	}
+	if dst.I16[0] != 32767 || dst.I16[1] != 16484 {
+		t.Errorf("expected saturated samples, got %v", dst.I16)
+	}
+}
+
+func TestMix_InvalidBuffers(t *testing.T) {
+	src := &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{0.5}}
+	tests := []struct {
+		name string
+		dst  audio.Buffer
+		srcs []Source
+		err  error
+	}{
+		{"nil destination", nil, []Source{{Buffer: src}}, audio.ErrInvalidBuffer},
+		{"nil source", &audio.FloatBuffer{Format: audio.FormatMono44100}, []Source{{Buffer: src}, {}}, audio.ErrInvalidBuffer},
+		{"no channels", &audio.PlanarFloatBuffer{Format: audio.FormatMono44100}, []Source{{Buffer: src}}, audio.ErrInvalidBuffer},
+		{"no format", &audio.FloatBuffer{}, []Source{{Buffer: src}}, audio.ErrNilFormat},
+	}
+	for _, tt := range tests {
+		if err := Mix(tt.dst, tt.srcs...); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestMixer_Clip(t *testing.T) {
+	tests := []struct {
+		mode ClipMode
+		want []float64
+	}{
+		{NoClip, []float64{1.5, 0.5, -0.9}},
+		{HardClip, []float64{1, 0.5, -0.9}},
+		{SoftClip, []float64{0.8 + 0.2*math.Tanh(3.5), 0.5, -(0.8 + 0.2*math.Tanh(0.5))}},
+		{Limit, []float64{1, 0.5 / 1.5, -0.9 / 1.5}},
+	}
+	for _, tt := range tests {
+		dst := &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{1, 0, -0.4}}
+		m := Mixer{Clip: tt.mode}
+		if err := m.Mix(dst, Source{Buffer: &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{0.5, 0.5, -0.5}}}); err != nil {
+			t.Fatal(err)
+		}
+		if !approxSlice(dst.Data, tt.want) {
+			t.Errorf("mode %d: expected %v, got %v", tt.mode, tt.want, dst.Data)
+		}
+	}
+}
+
+func TestMixer_SampleRates(t *testing.T) {
+	dst := &audio.FloatBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: 48000}}
+	src := &audio.FloatBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: 24000}, Data: make([]float64, 100)}
+	if err := Mix(dst, Source{Buffer: src}); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	if len(dst.Data) != 0 {
+		t.Errorf("expected dst to be left untouched, got %d samples", len(dst.Data))
+	}
+	m := Mixer{Resample: true, Quality: resample.Linear}
+	if err := m.Mix(dst, Source{Buffer: src}); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst.Data) != 200 {
+		t.Errorf("expected 200 resampled frames, got %d", len(dst.Data))
+	}
+}
+
+func TestMix_Channels(t *testing.T) {
+	dst := &audio.FloatBuffer{Format: audio.FormatMono44100}
+	src := &audio.FloatBuffer{Format: audio.FormatStereo44100, Data: []float64{0.5, 0.5}}
+	if err := Mix(dst, Source{Buffer: src, Offset: -1}, Source{Buffer: src}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{math.Sqrt2 / 2}; !approxSlice(dst.Data, want) {
+		t.Errorf("expected %v, got %v", want, dst.Data)
+	}
+	six := &audio.FloatBuffer{Format: &audio.Format{NumChannels: 6, SampleRate: 44100}}
+	if err := Mix(six, Source{Buffer: src}); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+}
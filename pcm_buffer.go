@@ -1,7 +1,5 @@
 package audio
 
-import "math"
-
 // PCMDataFormat is an enum type to indicate the underlying data format used.
 type PCMDataFormat uint8
 
@@ -18,6 +16,15 @@ const (
 	DataTypeF32
 	// DataTypeF64 indicates that the content of the audio buffer made of 64-bit floats.
 	DataTypeF64
+	// DataTypeU8 indicates that the content of the audio buffer made of unsigned 8-bit integers,
+	// 128 being the zero value (as in 8-bit WAV files).
+	DataTypeU8
+	// DataTypeI24 indicates that the content of the audio buffer made of packed little endian
+	// 24-bit integers.
+	DataTypeI24
+	// DataTypeI24BE indicates that the content of the audio buffer made of packed big endian
+	// 24-bit integers.
+	DataTypeI24BE
 )
 
 var _ Buffer = (*PCMBuffer)(nil)
@@ -40,12 +47,20 @@ type PCMBuffer struct {
 	F32 []float32
 	// F64 is a store for audio samples data as float64.
 	F64 []float64
+	// U8 is a store for audio sample data as unsigned integers, 128 being
+	// the zero value.
+	U8 []uint8
+	// I24 is a store for audio sample data as packed 3-byte integers using
+	// the endianness of the data type (DataTypeI24 or DataTypeI24BE).
+	I24 []byte
 	// DataType indicates the primary format used for the underlying data.
 	// The consumer of the buffer might want to look at this value to know what store
 	// to use to optimaly retrieve data.
 	DataType PCMDataFormat
 	// SourceBitDepth helps us know if the source was encoded on
 	// 1 (int8), 2 (int16), 3(int24), 4(int32), 8(int64) bytes.
+	// Unlike the other buffers, it is expressed in bytes. If not set, the
+	// bit depth of the format's sample encoding is used.
 	SourceBitDepth uint8
 }
 
@@ -66,6 +81,10 @@ func (b *PCMBuffer) Len() int {
 		return len(b.F32)
 	case DataTypeF64:
 		return len(b.F64)
+	case DataTypeU8:
+		return len(b.U8)
+	case DataTypeI24, DataTypeI24BE:
+		return len(b.I24) / 3
 	default:
 		return 0
 	}
@@ -94,40 +113,29 @@ func (b *PCMBuffer) NumFrames() int {
 
 // AsFloatBuffer returns a copy of this buffer but with data converted to floats.
 func (b *PCMBuffer) AsFloatBuffer() *FloatBuffer {
-	newB := &FloatBuffer{}
+	newB := &FloatBuffer{SourceBitDepth: b.sourceBitDepth()}
 	newB.Data = b.AsF64()
-	if b.Format != nil {
-		newB.Format = &Format{
-			NumChannels: b.Format.NumChannels,
-			SampleRate:  b.Format.SampleRate,
-		}
-	}
+	newB.Format = b.Format.clone()
 	return newB
 }
 
 // AsFloat32Buffer implements the Buffer interface and returns a float 32 version of itself.
 func (b *PCMBuffer) AsFloat32Buffer() *Float32Buffer {
-	newB := &Float32Buffer{}
+	newB := &Float32Buffer{SourceBitDepth: b.sourceBitDepth()}
 	newB.Data = b.AsF32()
-	if b.Format != nil {
-		newB.Format = &Format{
-			NumChannels: b.Format.NumChannels,
-			SampleRate:  b.Format.SampleRate,
-		}
-	}
+	newB.Format = b.Format.clone()
 	return newB
 }
 
-// AsIntBuffer returns a copy of this buffer but with data truncated to Ints.
+// AsIntBuffer returns a copy of this buffer but with data converted to Ints,
+// see AsI32.
 func (b *PCMBuffer) AsIntBuffer() *IntBuffer {
-	newB := &IntBuffer{}
-	newB.Data = b.AsInt()
-	if b.Format != nil {
-		newB.Format = &Format{
-			NumChannels: b.Format.NumChannels,
-			SampleRate:  b.Format.SampleRate,
-		}
+	newB := &IntBuffer{SourceBitDepth: b.storeBitDepth(DataTypeI32)}
+	if b.DataType == DataTypeI32 {
+		newB.SourceBitDepth = b.intBitDepth()
 	}
+	newB.Data = b.AsInt()
+	newB.Format = b.Format.clone()
 	return newB
 }
 
@@ -138,31 +146,10 @@ func (b *PCMBuffer) AsI8() (out []int8) {
 	if b == nil {
 		return nil
 	}
-	switch b.DataType {
-	case DataTypeI8:
+	if b.DataType == DataTypeI8 {
 		return b.I8
-	case DataTypeI16:
-		out = make([]int8, len(b.I16))
-		for i := 0; i < len(b.I16); i++ {
-			out[i] = int8(b.I16[i])
-		}
-	case DataTypeI32:
-		out = make([]int8, len(b.I32))
-		for i := 0; i < len(b.I32); i++ {
-			out[i] = int8(b.I32[i])
-		}
-	case DataTypeF32:
-		out = make([]int8, len(b.F32))
-		for i := 0; i < len(b.F32); i++ {
-			out[i] = int8(b.F32[i])
-		}
-	case DataTypeF64:
-		out = make([]int8, len(b.F64))
-		for i := 0; i < len(b.F64); i++ {
-			out[i] = int8(b.F64[i])
-		}
 	}
-	return out
+	return convertPCM[int8](b, b.storeBitDepth(DataTypeI8))
 }
 
 // AsI16 returns the buffer's samples as int16 sample values.
@@ -172,155 +159,180 @@ func (b *PCMBuffer) AsI16() (out []int16) {
 	if b == nil {
 		return nil
 	}
-	switch b.DataType {
-	case DataTypeI8:
-		out = make([]int16, len(b.I8))
-		for i := 0; i < len(b.I8); i++ {
-			out[i] = int16(b.I8[i])
-		}
-	case DataTypeI16:
+	if b.DataType == DataTypeI16 {
 		return b.I16
-	case DataTypeI32:
-		out = make([]int16, len(b.I32))
-		for i := 0; i < len(b.I32); i++ {
-			out[i] = int16(b.I32[i])
-		}
-	case DataTypeF32:
-		out = make([]int16, len(b.F32))
-		for i := 0; i < len(b.F32); i++ {
-			out[i] = int16(b.F32[i])
-		}
-	case DataTypeF64:
-		out = make([]int16, len(b.F64))
-		for i := 0; i < len(b.F64); i++ {
-			out[i] = int16(b.F64[i])
-		}
 	}
-	return out
+	return convertPCM[int16](b, b.storeBitDepth(DataTypeI16))
 }
 
 // AsI32 returns the buffer's samples as int32 sample values.
 // If the buffer isn't in this format, a copy is created and converted.
-// Note that converting a float to an int might result in unexpected truncations.
+// Samples are scaled to 32 bits, or to 24 bits if the source was encoded
+// on 3 bytes, float samples being rounded and clipped (see Float64ToInt).
 func (b *PCMBuffer) AsI32() (out []int32) {
 	if b == nil {
 		return nil
 	}
-	switch b.DataType {
-	case DataTypeI8:
-		out = make([]int32, len(b.I8))
-		for i := 0; i < len(b.I8); i++ {
-			out[i] = int32(b.I8[i])
-		}
-	case DataTypeI16:
-		out = make([]int32, len(b.I16))
-		for i := 0; i < len(b.I16); i++ {
-			out[i] = int32(b.I16[i])
-		}
-	case DataTypeI32:
+	if b.DataType == DataTypeI32 {
 		return b.I32
-	case DataTypeF32:
-		out = make([]int32, len(b.F32))
-		for i := 0; i < len(b.F32); i++ {
-			out[i] = int32(b.F32[i])
-		}
-	case DataTypeF64:
-		out = make([]int32, len(b.F64))
-		for i := 0; i < len(b.F64); i++ {
-			out[i] = int32(b.F64[i])
-		}
 	}
-	return out
+	return convertPCM[int32](b, b.storeBitDepth(DataTypeI32))
+}
+
+// AsU8 returns the buffer's samples as unsigned 8-bit sample values, 128
+// being the zero value. If the buffer isn't in this format, a copy is created
+// and converted.
+// Note that converting might result in loss of resolution.
+func (b *PCMBuffer) AsU8() (out []uint8) {
+	if b == nil {
+		return nil
+	}
+	if b.DataType == DataTypeU8 {
+		return b.U8
+	}
+	return packU8(convertPCM[int32](b, 8))
+}
+
+// AsI24 returns the buffer's samples as packed little endian 24-bit sample
+// values. If the buffer isn't in this format, a copy is created and converted.
+func (b *PCMBuffer) AsI24() (out []byte) {
+	return b.asI24(DataTypeI24)
+}
+
+// AsI24BE returns the buffer's samples as packed big endian 24-bit sample
+// values. If the buffer isn't in this format, a copy is created and converted.
+func (b *PCMBuffer) AsI24BE() (out []byte) {
+	return b.asI24(DataTypeI24BE)
+}
+
+func (b *PCMBuffer) asI24(t PCMDataFormat) []byte {
+	if b == nil {
+		return nil
+	}
+	if b.DataType == t {
+		return b.I24
+	}
+	return packI24(convertPCM[int32](b, 24), t == DataTypeI24BE)
 }
 
 // AsInt returns the buffer content as integers (int32s).
 // It's recommended to avoid this method since it creates
 // an extra copy of the buffer content.
 func (b *PCMBuffer) AsInt() (out []int) {
-	int32s := b.AsI32()
-	out = make([]int, len(int32s))
-	for i := 0; i < len(int32s); i++ {
-		out[i] = int(int32s[i])
+	if b == nil {
+		return nil
 	}
-	return out
+	if b.DataType == DataTypeI32 {
+		out = make([]int, len(b.I32))
+		ConvertSamples(out, b.I32, 32, 32)
+		return out
+	}
+	return convertPCM[int](b, b.storeBitDepth(DataTypeI32))
 }
 
 // AsF32 returns the buffer's samples as float32 sample values.
 // If the buffer isn't in this format, a copy is created and converted.
-// Note that converting might result in unexpected truncations.
+// Integer samples are normalized to the [-1, 1) range, see IntToFloat64.
 func (b *PCMBuffer) AsF32() (out []float32) {
 	if b == nil {
 		return nil
 	}
-	switch b.DataType {
-	case DataTypeI8:
-		bitDepth := b.calculateIntBitDepth()
-		factor := math.Pow(2, 8*float64(bitDepth/8)-1)
-		out = make([]float32, len(b.I8))
-		for i := 0; i < len(b.I8); i++ {
-			out[i] = float32(float64(int64(b.I8[i])) / factor)
-		}
-	case DataTypeI16:
-		bitDepth := b.calculateIntBitDepth()
-		factor := math.Pow(2, 8*float64(bitDepth/8)-1)
-		out = make([]float32, len(b.I16))
-		for i := 0; i < len(b.I16); i++ {
-			out[i] = float32(float64(int64(b.I16[i])) / factor)
-		}
-	case DataTypeI32:
-		bitDepth := b.calculateIntBitDepth()
-		factor := math.Pow(2, 8*float64(bitDepth/8)-1)
-		out = make([]float32, len(b.I16))
-		for i := 0; i < len(b.I16); i++ {
-			out[i] = float32(float64(int64(b.I16[i])) / factor)
-		}
-	case DataTypeF32:
+	if b.DataType == DataTypeF32 {
 		return b.F32
-	case DataTypeF64:
-		out = make([]float32, len(b.F64))
-		for i := 0; i < len(b.F64); i++ {
-			out[i] = float32(b.F64[i])
-		}
 	}
-	return out
+	return convertPCM[float32](b, 0)
 }
 
 // AsF64 returns the buffer's samples as float64 sample values.
 // If the buffer isn't in this format, a copy is created and converted.
-// Note that converting might result in unexpected truncations.
+// Integer samples are normalized to the [-1, 1) range, see IntToFloat64.
 func (b *PCMBuffer) AsF64() (out []float64) {
 	if b == nil {
 		return nil
 	}
+	if b.DataType == DataTypeF64 {
+		return b.F64
+	}
+	return convertPCM[float64](b, 0)
+}
+
+// convertPCM returns a copy of the primary store of b converted to T,
+// integer samples being scaled to bitDepth bits, see ConvertSamples.
+func convertPCM[T Sample](b *PCMBuffer, bitDepth int) []T {
+	out := make([]T, b.Len())
+	from := b.intBitDepth()
 	switch b.DataType {
 	case DataTypeI8:
-		bitDepth := b.calculateIntBitDepth()
-		factor := math.Pow(2, 8*float64(bitDepth/8)-1)
-		out = make([]float64, len(b.I8))
-		for i := 0; i < len(b.I8); i++ {
-			out[i] = float64(int64(b.I8[i])) / factor
-		}
+		ConvertSamples(out, b.I8, bitDepth, from)
 	case DataTypeI16:
-		bitDepth := b.calculateIntBitDepth()
-		factor := math.Pow(2, 8*float64(bitDepth/8)-1)
-		out = make([]float64, len(b.I16))
-		for i := 0; i < len(b.I16); i++ {
-			out[i] = float64(int64(b.I16[i])) / factor
-		}
+		ConvertSamples(out, b.I16, bitDepth, from)
 	case DataTypeI32:
-		bitDepth := b.calculateIntBitDepth()
-		factor := math.Pow(2, 8*float64(bitDepth/8)-1)
-		out = make([]float64, len(b.I16))
-		for i := 0; i < len(b.I16); i++ {
-			out[i] = float64(int64(b.I16[i])) / factor
-		}
+		ConvertSamples(out, b.I32, bitDepth, from)
 	case DataTypeF32:
-		out = make([]float64, len(b.F32))
-		for i := 0; i < len(b.F32); i++ {
-			out[i] = float64(b.F32[i])
-		}
+		ConvertSamples(out, b.F32, bitDepth, from)
 	case DataTypeF64:
-		return b.F64
+		ConvertSamples(out, b.F64, bitDepth, from)
+	case DataTypeU8:
+		convertChunks(out, bitDepth, from, func(i int) int32 { return int32(b.U8[i]) - 128 })
+	case DataTypeI24, DataTypeI24BE:
+		convertChunks(out, bitDepth, from, b.int24)
+	}
+	return out
+}
+
+// convertChunks converts the len(out) integer samples returned by get,
+// going through a small buffer to avoid allocations.
+func convertChunks[T Sample](out []T, bitDepth, from int, get func(i int) int32) {
+	var chunk [256]int32
+	for start := 0; start < len(out); start += len(chunk) {
+		c := chunk[:]
+		if n := len(out) - start; n < len(c) {
+			c = c[:n]
+		}
+		for i := range c {
+			c[i] = get(start + i)
+		}
+		ConvertSamples(out[start:], c, bitDepth, from)
+	}
+}
+
+// int24 returns the sample i of the packed 24-bit store.
+func (b *PCMBuffer) int24(i int) int32 {
+	if b.DataType == DataTypeI24BE {
+		return Int24BETo32(b.I24[3*i:])
+	}
+	return Int24LETo32(b.I24[3*i:])
+}
+
+// setPacked replaces the packed store (U8 or I24) of b by the samples s
+// encoded on the bit depth of the store.
+func (b *PCMBuffer) setPacked(s []int32) {
+	switch b.DataType {
+	case DataTypeU8:
+		b.U8 = packU8(s)
+	case DataTypeI24, DataTypeI24BE:
+		b.I24 = packI24(s, b.DataType == DataTypeI24BE)
+	}
+}
+
+// packU8 converts signed 8-bit samples to unsigned ones.
+func packU8(s []int32) []uint8 {
+	out := make([]uint8, len(s))
+	for i, v := range s {
+		out[i] = uint8(v + 128)
+	}
+	return out
+}
+
+// packI24 converts 24-bit samples to packed 3-byte samples.
+func packI24(s []int32, bigEndian bool) []byte {
+	out := make([]byte, 3*len(s))
+	for i, v := range s {
+		if bigEndian {
+			PutInt24BE(out[3*i:], v)
+		} else {
+			PutInt24LE(out[3*i:], v)
+		}
 	}
 	return out
 }
@@ -331,7 +343,7 @@ func (b *PCMBuffer) Clone() Buffer {
 	if b == nil {
 		return nil
 	}
-	newB := &PCMBuffer{DataType: b.DataType}
+	newB := &PCMBuffer{DataType: b.DataType, SourceBitDepth: b.SourceBitDepth}
 	switch b.DataType {
 	case DataTypeI8:
 		newB.I8 = make([]int8, len(b.I8))
@@ -348,12 +360,15 @@ func (b *PCMBuffer) Clone() Buffer {
 	case DataTypeF64:
 		newB.F64 = make([]float64, len(b.F64))
 		copy(newB.F64, b.F64)
+	case DataTypeU8:
+		newB.U8 = make([]uint8, len(b.U8))
+		copy(newB.U8, b.U8)
+	case DataTypeI24, DataTypeI24BE:
+		newB.I24 = make([]byte, len(b.I24))
+		copy(newB.I24, b.I24)
 	}
 
-	newB.Format = &Format{
-		NumChannels: b.Format.NumChannels,
-		SampleRate:  b.Format.SampleRate,
-	}
+	newB.Format = b.Format.clone()
 	return newB
 }
 
@@ -364,98 +379,131 @@ func (b *PCMBuffer) SwitchPrimaryType(t PCMDataFormat) {
 	if b == nil || t == b.DataType {
 		return
 	}
+	// keep track of the bit depth of the samples in the new store
+	bitDepth := b.sourceBitDepth()
+	if t.isInt() {
+		bitDepth = b.storeBitDepth(t)
+	}
+	// convert the samples before releasing the other stores
+	var newB PCMBuffer
 	switch t {
 	case DataTypeI8:
-		b.I8 = b.AsI8()
-		b.I16 = nil
-		b.I32 = nil
-		b.F32 = nil
-		b.F64 = nil
+		newB.I8 = b.AsI8()
 	case DataTypeI16:
-		b.I8 = nil
-		b.I16 = b.AsI16()
-		b.I32 = nil
-		b.F32 = nil
-		b.F64 = nil
+		newB.I16 = b.AsI16()
 	case DataTypeI32:
-		b.I8 = nil
-		b.I16 = nil
-		b.I32 = b.AsI32()
-		b.F32 = nil
-		b.F64 = nil
+		newB.I32 = b.AsI32()
 	case DataTypeF32:
-		b.I8 = nil
-		b.I16 = nil
-		b.I32 = nil
-		b.F32 = b.AsF32()
-		b.F64 = nil
+		newB.F32 = b.AsF32()
 	case DataTypeF64:
-		b.I8 = nil
-		b.I16 = nil
-		b.I32 = nil
-		b.F32 = nil
-		b.F64 = b.AsF64()
+		newB.F64 = b.AsF64()
+	case DataTypeU8:
+		newB.U8 = b.AsU8()
+	case DataTypeI24:
+		newB.I24 = b.AsI24()
+	case DataTypeI24BE:
+		newB.I24 = b.AsI24BE()
 	}
+	b.I8, b.I16, b.I32, b.F32, b.F64 = newB.I8, newB.I16, newB.I32, newB.F32, newB.F64
+	b.U8, b.I24 = newB.U8, newB.I24
 
 	b.DataType = t
+	if bitDepth > 0 {
+		b.SourceBitDepth = uint8(bitDepth / 8)
+	}
 }
 
-// calculateIntBithDepth looks at the int values in the buffer and returns
-// the required lowest bit depth.
-func (b *PCMBuffer) calculateIntBitDepth() uint8 {
-	if b == nil {
-		return 0
-	}
-	bitDepth := b.SourceBitDepth
-	if bitDepth != 0 {
-		return bitDepth
+// isInt returns true if the primary store of the buffer holds integers.
+func (b *PCMBuffer) isInt() bool {
+	return b.DataType.isInt()
+}
+
+// isInt returns true if the data format is an integer format.
+func (f PCMDataFormat) isInt() bool {
+	switch f {
+	case DataTypeI8, DataTypeI16, DataTypeI32, DataTypeU8, DataTypeI24, DataTypeI24BE:
+		return true
 	}
-	var max int64
-	switch b.DataType {
-	case DataTypeI8:
-		var i8max int8
-		for _, s := range b.I8 {
-			if s > i8max {
-				i8max = s
-			}
-		}
-		max = int64(i8max)
+	return false
+}
+
+// containerBitDepth returns the size in bits of a sample of the data format.
+func (f PCMDataFormat) containerBitDepth() int {
+	switch f {
+	case DataTypeI8, DataTypeU8:
+		return 8
 	case DataTypeI16:
-		var i16max int16
-		for _, s := range b.I16 {
-			if s > i16max {
-				i16max = s
-			}
-		}
-		max = int64(i16max)
-	case DataTypeI32:
-		var i32max int32
-		for _, s := range b.I32 {
-			if s > i32max {
-				i32max = s
-			}
-		}
-		max = int64(i32max)
-	default:
-		// This method is only meant to be used on int buffers.
-		return bitDepth
+		return 16
+	case DataTypeI24, DataTypeI24BE:
+		return 24
+	case DataTypeI32, DataTypeF32:
+		return 32
+	case DataTypeF64:
+		return 64
 	}
-	bitDepth = 8
-	if max > 127 {
-		bitDepth = 16
+	return 0
+}
+
+// intBitDepth returns the bit depth, in bits, of the samples held by the
+// integer primary store: the declared bit depth capped to the container or,
+// if not declared, the container width.
+func (b *PCMBuffer) intBitDepth() int {
+	bitDepth := b.declaredBitDepth()
+	if max := b.DataType.containerBitDepth(); bitDepth == 0 || bitDepth > max {
+		return max
 	}
-	// greater than int16, expecting int24
-	if max > 32767 {
-		bitDepth = 24
+	return bitDepth
+}
+
+// storeBitDepth returns the bit depth, in bits, that samples get scaled to
+// when converted to the integer store t. 24-bit samples are stored as is in
+// int32s, otherwise the samples use the full range of the store.
+func (b *PCMBuffer) storeBitDepth(t PCMDataFormat) int {
+	if t == DataTypeI32 && b.declaredBitDepth() == 24 {
+		return 24
 	}
-	// int 32
-	if max > 8388607 {
-		bitDepth = 32
+	return t.containerBitDepth()
+}
+
+// sourceBitDepth returns the bit depth, in bits, of the source samples
+// or 0 if unknown.
+func (b *PCMBuffer) sourceBitDepth() int {
+	if b.isInt() {
+		return b.intBitDepth()
 	}
-	// int 64
-	if max > 4294967295 {
-		bitDepth = 64
+	return b.declaredBitDepth()
+}
+
+// declaredBitDepth returns the bit depth, in bits, of the source samples as
+// set by SourceBitDepth or by the format's sample encoding, or 0 if unknown.
+func (b *PCMBuffer) declaredBitDepth() int {
+	if b.SourceBitDepth != 0 {
+		return int(b.SourceBitDepth) * 8
 	}
+	return b.Format.bitDepth()
+}
 
-	return bitDepth
+// rescaleInt converts an integer sample encoded on from bits to a sample
+// encoded on to bits, saturating values that don't fit. Bit depths that
+// aren't in [1, 64] stand for the 64 bits of an int64.
+func rescaleInt(s int64, from, to int) int64 {
+	if from < 1 || from > 64 {
+		from = 64
+	}
+	if to < 1 || to > 64 {
+		to = 64
+	}
+	if from < to {
+		s <<= uint(to - from)
+	} else if from > to {
+		s >>= uint(from - to)
+	}
+	max := int64(1)<<uint(to-1) - 1
+	if s > max {
+		return max
+	}
+	if s < -max-1 {
+		return -max - 1
+	}
+	return s
 }
@@ -0,0 +1,195 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPCMBuffer_FloatIntRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataType PCMDataFormat
+		want     []float64
+	}{
+		{"int8", DataTypeI8, []float64{-1, -0.5, 0, 0.5, 127.0 / 128}},
+		{"int16", DataTypeI16, []float64{-1, -0.5, 0, 0.5, 32767.0 / 32768}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &PCMBuffer{
+				Format:   FormatMono44100,
+				F64:      []float64{-1, -0.5, 0, 0.5, 1},
+				DataType: DataTypeF64,
+			}
+			buf.SwitchPrimaryType(tt.dataType)
+			buf.SwitchPrimaryType(DataTypeF64)
+			if !reflect.DeepEqual(buf.F64, tt.want) {
+				t.Errorf("expected %v got %v", tt.want, buf.F64)
+			}
+		})
+	}
+}
+
+func TestPCMBuffer_AsF64(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  *PCMBuffer
+		want []float64
+	}{
+		{"int8", &PCMBuffer{I8: []int8{-128, 64}, DataType: DataTypeI8, SourceBitDepth: 1}, []float64{-1, 0.5}},
+		{"int16", &PCMBuffer{I16: []int16{-32768, 16384}, DataType: DataTypeI16, SourceBitDepth: 2}, []float64{-1, 0.5}},
+		{"int16 holding 8 bit samples", &PCMBuffer{I16: []int16{-128, 64}, DataType: DataTypeI16, SourceBitDepth: 1}, []float64{-1, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.buf.Format = FormatMono44100
+			if got := tt.buf.AsF64(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AsF64() = %v, want %v", got, tt.want)
+			}
+			if got := tt.buf.AsFloatBuffer().AsIntBuffer(); got.SourceBitDepth != int(tt.buf.SourceBitDepth)*8 {
+				t.Errorf("expected the source bit depth to be kept, got %d", got.SourceBitDepth)
+			}
+		})
+	}
+}
+
+// pcmFixture returns a mono buffer of the given type holding the
+// -1, -0.5, 0, 0.25, 0.5 normalized values.
+func pcmFixture(t PCMDataFormat) *PCMBuffer {
+	b := &PCMBuffer{Format: FormatMono44100, DataType: t}
+	switch t {
+	case DataTypeI8:
+		b.I8 = []int8{-128, -64, 0, 32, 64}
+		b.SourceBitDepth = 1
+	case DataTypeI16:
+		b.I16 = []int16{-32768, -16384, 0, 8192, 16384}
+		b.SourceBitDepth = 2
+	case DataTypeI32:
+		b.I32 = []int32{-1 << 31, -1 << 30, 0, 1 << 29, 1 << 30}
+		b.SourceBitDepth = 4
+	case DataTypeF32:
+		b.F32 = []float32{-1, -0.5, 0, 0.25, 0.5}
+	case DataTypeF64:
+		b.F64 = []float64{-1, -0.5, 0, 0.25, 0.5}
+	case DataTypeU8:
+		b.U8 = []uint8{0, 64, 128, 160, 192}
+		b.SourceBitDepth = 1
+	case DataTypeI24:
+		b.I24 = []byte{0, 0, 0x80, 0, 0, 0xc0, 0, 0, 0, 0, 0, 0x20, 0, 0, 0x40}
+		b.SourceBitDepth = 3
+	case DataTypeI24BE:
+		b.I24 = []byte{0x80, 0, 0, 0xc0, 0, 0, 0, 0, 0, 0x20, 0, 0, 0x40, 0, 0}
+		b.SourceBitDepth = 3
+	}
+	return b
+}
+
+func TestPCMBuffer_SwitchPrimaryType(t *testing.T) {
+	types := []struct {
+		name string
+		t    PCMDataFormat
+	}{
+		{"I8", DataTypeI8},
+		{"I16", DataTypeI16},
+		{"I32", DataTypeI32},
+		{"F32", DataTypeF32},
+		{"F64", DataTypeF64},
+		{"U8", DataTypeU8},
+		{"I24", DataTypeI24},
+		{"I24BE", DataTypeI24BE},
+	}
+	for _, src := range types {
+		for _, dst := range types {
+			t.Run(src.name+" to "+dst.name, func(t *testing.T) {
+				buf := pcmFixture(src.t)
+				buf.SwitchPrimaryType(dst.t)
+				if buf.DataType != dst.t {
+					t.Fatalf("expected the data type to be %d, got %d", dst.t, buf.DataType)
+				}
+				if buf.Len() != 5 {
+					t.Fatalf("expected 5 samples, got %d", buf.Len())
+				}
+				want := pcmFixture(dst.t)
+				if dst.t == DataTypeI32 && buf.SourceBitDepth == 3 {
+					// 24-bit samples are stored as is in int32s
+					want.I32 = []int32{-1 << 23, -1 << 22, 0, 1 << 21, 1 << 22}
+				}
+				if !reflect.DeepEqual(buf.I8, want.I8) ||
+					!reflect.DeepEqual(buf.I16, want.I16) ||
+					!reflect.DeepEqual(buf.I32, want.I32) ||
+					!reflect.DeepEqual(buf.F32, want.F32) ||
+					!reflect.DeepEqual(buf.F64, want.F64) ||
+					!reflect.DeepEqual(buf.U8, want.U8) ||
+					!reflect.DeepEqual(buf.I24, want.I24) {
+					t.Errorf("expected %+v got %+v", want, buf)
+				}
+				if got := buf.AsF64(); !reflect.DeepEqual(got, pcmFixture(DataTypeF64).F64) {
+					t.Errorf("expected the normalized values to be kept, got %v", got)
+				}
+			})
+		}
+	}
+}
+
+func TestPCMBuffer_IntRescaling(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  *PCMBuffer
+		to   PCMDataFormat
+		want interface{}
+	}{
+		{"int16 to int8 shifts",
+			&PCMBuffer{I16: []int16{300, -300, 32767, -32768}, DataType: DataTypeI16, SourceBitDepth: 2},
+			DataTypeI8, []int8{1, -2, 127, -128}},
+		{"int8 to int16 shifts",
+			&PCMBuffer{I8: []int8{1, -2, 127, -128}, DataType: DataTypeI8, SourceBitDepth: 1},
+			DataTypeI16, []int16{256, -512, 32512, -32768}},
+		{"int24 to int16 shifts",
+			&PCMBuffer{I32: []int32{8388607, -8388608, 256}, DataType: DataTypeI32, SourceBitDepth: 3},
+			DataTypeI16, []int16{32767, -32768, 1}},
+		{"out of range int24 saturates",
+			&PCMBuffer{I32: []int32{9000000, -9000000}, DataType: DataTypeI32, SourceBitDepth: 3},
+			DataTypeI16, []int16{32767, -32768}},
+		{"float to int24 keeps 24 bits",
+			&PCMBuffer{F64: []float64{0.5, -1, 2}, DataType: DataTypeF64, SourceBitDepth: 3},
+			DataTypeI32, []int32{4194304, -8388608, 8388607}},
+		{"int24 to float",
+			&PCMBuffer{I32: []int32{4194304, -8388608}, DataType: DataTypeI32, SourceBitDepth: 3},
+			DataTypeF32, []float32{0.5, -1}},
+		{"packed int24 to int16 shifts",
+			&PCMBuffer{I24: []byte{0xff, 0xff, 0x7f, 0, 0x01, 0}, DataType: DataTypeI24},
+			DataTypeI16, []int16{32767, 1}},
+		{"uint8 to int16 shifts",
+			&PCMBuffer{U8: []uint8{0, 129, 255}, DataType: DataTypeU8},
+			DataTypeI16, []int16{-32768, 256, 32512}},
+		{"float to uint8 clips",
+			&PCMBuffer{F64: []float64{-2, 0, 0.5, 2}, DataType: DataTypeF64},
+			DataTypeU8, []uint8{0, 128, 192, 255}},
+		{"int16 to packed big endian int24",
+			&PCMBuffer{I16: []int16{-32768, 1}, DataType: DataTypeI16, SourceBitDepth: 2},
+			DataTypeI24BE, []byte{0x80, 0, 0, 0, 0x01, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.buf.Format = FormatMono44100
+			var got interface{}
+			switch tt.to {
+			case DataTypeI8:
+				got = tt.buf.AsI8()
+			case DataTypeI16:
+				got = tt.buf.AsI16()
+			case DataTypeI32:
+				got = tt.buf.AsI32()
+			case DataTypeF32:
+				got = tt.buf.AsF32()
+			case DataTypeU8:
+				got = tt.buf.AsU8()
+			case DataTypeI24BE:
+				got = tt.buf.AsI24BE()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package audio
+
+var _ Buffer = (*PlanarFloatBuffer)(nil)
+
+// PlanarFloatBuffer is an audio buffer with its PCM data formatted as float64
+// and stored per channel (planar) instead of being interleaved.
+type PlanarFloatBuffer struct {
+	// Format is the representation of the underlying data format
+	Format *Format
+	// Data holds the buffer PCM data of each channel as floats
+	Data [][]float64
+	// SourceBitDepth helps us know if the source was encoded on
+	// 8, 16, 24, 32, 64 bits.
+	SourceBitDepth int
+}
+
+// PCMFormat returns the buffer format information.
+func (buf *PlanarFloatBuffer) PCMFormat() *Format { return buf.Format }
+
+// NumFrames returns the number of frames contained in the buffer, that is to
+// say the length of the shortest channel.
+func (buf *PlanarFloatBuffer) NumFrames() int {
+	if buf == nil || len(buf.Data) == 0 {
+		return 0
+	}
+	frames := len(buf.Data[0])
+	for _, ch := range buf.Data[1:] {
+		if len(ch) < frames {
+			frames = len(ch)
+		}
+	}
+	return frames
+}
+
+// Channel returns the samples of the channel i, sharing the buffer's storage,
+// or nil if the buffer doesn't have such a channel.
+func (buf *PlanarFloatBuffer) Channel(i int) []float64 {
+	if i < 0 || i >= len(buf.Data) {
+		return nil
+	}
+	return buf.Data[i]
+}
+
+// AsFloatBuffer returns a copy of this buffer with its data interleaved.
+func (buf *PlanarFloatBuffer) AsFloatBuffer() *FloatBuffer {
+	newB := &FloatBuffer{SourceBitDepth: buf.SourceBitDepth}
+	newB.Data = make([]float64, len(buf.Data)*buf.NumFrames())
+	Interleave(newB.Data, buf.Data)
+	newB.Format = buf.interleavedFormat()
+	return newB
+}
+
+// AsFloat32Buffer returns a copy of this buffer with its data interleaved
+// and converted to float 32.
+func (buf *PlanarFloatBuffer) AsFloat32Buffer() *Float32Buffer {
+	return buf.AsFloatBuffer().AsFloat32Buffer()
+}
+
+// AsIntBuffer returns a copy of this buffer with its data interleaved and
+// scaled to ints, see FloatBuffer.AsIntBuffer.
+func (buf *PlanarFloatBuffer) AsIntBuffer() *IntBuffer {
+	return buf.AsFloatBuffer().AsIntBuffer()
+}
+
+// Clone creates a clean clone that can be modified without
+// changing the source buffer.
+func (buf *PlanarFloatBuffer) Clone() Buffer {
+	if buf == nil {
+		return nil
+	}
+	newB := &PlanarFloatBuffer{SourceBitDepth: buf.SourceBitDepth}
+	newB.Data = make([][]float64, len(buf.Data))
+	for i, ch := range buf.Data {
+		newB.Data[i] = make([]float64, len(ch))
+		copy(newB.Data[i], ch)
+	}
+	newB.Format = buf.Format.clone()
+	return newB
+}
+
+// interleavedFormat returns a copy of the buffer format, making sure the
+// number of channels matches the planar data.
+func (buf *PlanarFloatBuffer) interleavedFormat() *Format {
+	f := buf.Format.clone()
+	if f == nil {
+		f = &Format{}
+	}
+	f.NumChannels = len(buf.Data)
+	return f
+}
+
+// AsPlanarFloatBuffer returns a copy of this buffer with its data split per
+// channel.
+func (buf *FloatBuffer) AsPlanarFloatBuffer() *PlanarFloatBuffer {
+	nc := numChannels(buf.Format)
+	frames := buf.NumFrames()
+	newB := &PlanarFloatBuffer{SourceBitDepth: buf.SourceBitDepth}
+	newB.Data = make([][]float64, nc)
+	for i := range newB.Data {
+		newB.Data[i] = make([]float64, frames)
+	}
+	Deinterleave(newB.Data, buf.Data)
+	newB.Format = buf.Format.clone()
+	return newB
+}
+
+// Interleave copies the planar channels of src into the interleaved dst
+// slice. It copies as many frames as both sides can hold and returns the
+// number of copied frames. Interleave doesn't allocate.
+func Interleave[T Sample](dst []T, src [][]T) int {
+	nc := len(src)
+	if nc == 0 {
+		return 0
+	}
+	frames := len(dst) / nc
+	for _, ch := range src {
+		if len(ch) < frames {
+			frames = len(ch)
+		}
+	}
+	for c, ch := range src {
+		for i := 0; i < frames; i++ {
+			dst[i*nc+c] = ch[i]
+		}
+	}
+	return frames
+}
+
+// Deinterleave copies the interleaved samples of src into the planar
+// channels of dst, the number of channels being len(dst). It copies as many
+// frames as both sides can hold and returns the number of copied frames.
+// Deinterleave doesn't allocate.
+func Deinterleave[T Sample](dst [][]T, src []T) int {
+	nc := len(dst)
+	if nc == 0 {
+		return 0
+	}
+	frames := len(src) / nc
+	for _, ch := range dst {
+		if len(ch) < frames {
+			frames = len(ch)
+		}
+	}
+	for c, ch := range dst {
+		for i := 0; i < frames; i++ {
+			ch[i] = src[i*nc+c]
+		}
+	}
+	return frames
+}
+
+// Channel returns a copy of the samples of the channel i, or nil if the
+// buffer doesn't have such a channel.
+func (buf *FloatBuffer) Channel(i int) []float64 {
+	return buf.typed().Channel(i)
+}
+
+// Channel returns a copy of the samples of the channel i, or nil if the
+// buffer doesn't have such a channel.
+func (buf *Float32Buffer) Channel(i int) []float32 {
+	return buf.typed().Channel(i)
+}
+
+// Channel returns a copy of the samples of the channel i, or nil if the
+// buffer doesn't have such a channel.
+func (buf *IntBuffer) Channel(i int) []int {
+	return channelSamples(buf.Data, numChannels(buf.Format), i)
+}
+
+// Channel returns a mono copy of the channel i, using the same data type,
+// or nil if the buffer doesn't have such a channel.
+func (b *PCMBuffer) Channel(i int) *PCMBuffer {
+	nc := numChannels(b.Format)
+	if i < 0 || i >= nc {
+		return nil
+	}
+	newB := &PCMBuffer{DataType: b.DataType, SourceBitDepth: b.SourceBitDepth}
+	newB.Format = b.Format.clone()
+	if newB.Format != nil {
+		newB.Format.NumChannels = 1
+		newB.Format.Layout = ChannelLayout{}
+	}
+	switch b.DataType {
+	case DataTypeI8:
+		newB.I8 = channelSamples(b.I8, nc, i)
+	case DataTypeI16:
+		newB.I16 = channelSamples(b.I16, nc, i)
+	case DataTypeI32:
+		newB.I32 = channelSamples(b.I32, nc, i)
+	case DataTypeF32:
+		newB.F32 = channelSamples(b.F32, nc, i)
+	case DataTypeF64:
+		newB.F64 = channelSamples(b.F64, nc, i)
+	case DataTypeU8:
+		newB.U8 = channelSamples(b.U8, nc, i)
+	case DataTypeI24, DataTypeI24BE:
+		newB.I24 = make([]byte, 3*b.NumFrames())
+		for f := 0; f < len(newB.I24); f += 3 {
+			copy(newB.I24[f:f+3], b.I24[f*nc+3*i:])
+		}
+	}
+	return newB
+}
@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInterleave(t *testing.T) {
+	planar := [][]float64{{1, 2, 3}, {-1, -2, -3}}
+	dst := make([]float64, 6)
+	if n := Interleave(dst, planar); n != 3 {
+		t.Errorf("expected 3 frames to be interleaved, got %d", n)
+	}
+	want := []float64{1, -1, 2, -2, 3, -3}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("expected %v got %v", want, dst)
+	}
+
+	back := [][]float64{make([]float64, 3), make([]float64, 3)}
+	if n := Deinterleave(back, dst); n != 3 {
+		t.Errorf("expected 3 frames to be deinterleaved, got %d", n)
+	}
+	if !reflect.DeepEqual(back, planar) {
+		t.Errorf("expected %v got %v", planar, back)
+	}
+
+	// short destinations limit the number of frames
+	if n := Interleave(make([]float64, 5), planar); n != 2 {
+		t.Errorf("expected 2 frames to fit, got %d", n)
+	}
+	if n := Deinterleave([][]float64{make([]float64, 1), make([]float64, 3)}, dst); n != 1 {
+		t.Errorf("expected 1 frame to fit, got %d", n)
+	}
+	if n := Interleave(dst, nil); n != 0 {
+		t.Errorf("expected no frames without channels, got %d", n)
+	}
+}
+
+func TestPlanarFloatBuffer(t *testing.T) {
+	buf := &FloatBuffer{Format: FormatStereo44100, Data: []float64{0.5, -0.5, 0.25, -0.25}, SourceBitDepth: 16}
+	planar := buf.AsPlanarFloatBuffer()
+	if !reflect.DeepEqual(planar.Data, [][]float64{{0.5, 0.25}, {-0.5, -0.25}}) {
+		t.Errorf("unexpected planar data %v", planar.Data)
+	}
+	if planar.NumFrames() != 2 {
+		t.Errorf("expected 2 frames, got %d", planar.NumFrames())
+	}
+	if got := planar.AsFloatBuffer(); !reflect.DeepEqual(got.Data, buf.Data) || got.Format.NumChannels != 2 {
+		t.Errorf("unexpected interleaved buffer %+v", got)
+	}
+	if got := planar.AsIntBuffer().Data; !reflect.DeepEqual(got, []int{16384, -16384, 8192, -8192}) {
+		t.Errorf("unexpected int data %v", got)
+	}
+	if got := planar.AsFloat32Buffer().Data; !reflect.DeepEqual(got, []float32{0.5, -0.5, 0.25, -0.25}) {
+		t.Errorf("unexpected float32 data %v", got)
+	}
+
+	clone := planar.Clone().(*PlanarFloatBuffer)
+	clone.Data[0][0] = 0
+	if planar.Data[0][0] != 0.5 {
+		t.Error("modifying the clone changed the source buffer")
+	}
+	if ch := planar.Channel(1); &ch[0] != &planar.Data[1][0] {
+		t.Error("expected the channel to share the buffer storage")
+	}
+	if planar.Channel(2) != nil {
+		t.Error("expected no third channel")
+	}
+}
+
+func TestChannel(t *testing.T) {
+	fb := &FloatBuffer{Format: FormatStereo44100, Data: []float64{1, 2, 3, 4, 5, 6}}
+	if got := fb.Channel(1); !reflect.DeepEqual(got, []float64{2, 4, 6}) {
+		t.Errorf("unexpected channel %v", got)
+	}
+	if fb.Channel(2) != nil || fb.Channel(-1) != nil {
+		t.Error("expected nil for channels out of range")
+	}
+	f32 := &Float32Buffer{Format: FormatStereo44100, Data: []float32{1, 2, 3, 4}}
+	if got := f32.Channel(0); !reflect.DeepEqual(got, []float32{1, 3}) {
+		t.Errorf("unexpected channel %v", got)
+	}
+	ib := &IntBuffer{Format: FormatStereo44100, Data: []int{1, 2, 3, 4}}
+	if got := ib.Channel(1); !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Errorf("unexpected channel %v", got)
+	}
+	pb := &PCMBuffer{Format: FormatStereo44100, I16: []int16{1, 2, 3, 4}, DataType: DataTypeI16, SourceBitDepth: 2}
+	ch := pb.Channel(1)
+	if !reflect.DeepEqual(ch.I16, []int16{2, 4}) || ch.Format.NumChannels != 1 || ch.SourceBitDepth != 2 {
+		t.Errorf("unexpected channel %+v", ch)
+	}
+	if pb.Format.NumChannels != 2 {
+		t.Error("the source format was modified")
+	}
+	packed := &PCMBuffer{Format: FormatStereo44100, I24: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, DataType: DataTypeI24}
+	if got := packed.Channel(1).I24; !reflect.DeepEqual(got, []byte{4, 5, 6, 10, 11, 12}) {
+		t.Errorf("unexpected packed channel %v", got)
+	}
+}
@@ -0,0 +1,145 @@
+package process
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/go-audio/audio"
+)
+
+// Curve is the shape of a gain transition. Curves describe rising
+// transitions, falling transitions following the time reversed curve so a
+// fade out mirrors the fade in.
+type Curve uint8
+
+const (
+	// Linear changes the gain linearly.
+	Linear Curve = iota
+	// Exponential changes the gain linearly in decibels over a 60 dB
+	// range, sounding more natural than Linear for long fades.
+	Exponential
+	// EqualPower follows a quarter of a sine so crossfading a fade in and a
+	// fade out of the same length keeps a constant power.
+	EqualPower
+)
+
+// at returns the progress of the curve at t, in [0, 1].
+func (c Curve) at(t float64) float64 {
+	switch c {
+	case Exponential:
+		return (math.Pow(1000, t) - 1) / 999
+	case EqualPower:
+		return math.Sin(t * math.Pi / 2)
+	}
+	return t
+}
+
+// Point is a breakpoint of an Envelope.
+type Point struct {
+	// Frame is the position of the point.
+	Frame int
+	// Gain is the linear gain at the point.
+	Gain float64
+	// Curve is the shape of the transition from the previous point.
+	Curve Curve
+}
+
+// Envelope is a gain envelope described by breakpoints sorted by frame.
+// The gain before the first point is the gain of the first point and the
+// gain after the last point is the gain of the last point. An empty
+// envelope has a gain of 1.
+type Envelope []Point
+
+// Validate returns ErrInvalidEnvelope if the points aren't sorted by frame.
+func (e Envelope) Validate() error {
+	if !sort.SliceIsSorted(e, func(i, j int) bool { return e[i].Frame < e[j].Frame }) {
+		return ErrInvalidEnvelope
+	}
+	return nil
+}
+
+// At returns the gain of the envelope at the given frame.
+func (e Envelope) At(frame int) float64 {
+	if len(e) == 0 {
+		return 1
+	}
+	// index of the first point after the frame
+	i := sort.Search(len(e), func(i int) bool { return e[i].Frame > frame })
+	if i == 0 {
+		return e[0].Gain
+	}
+	if i == len(e) {
+		return e[len(e)-1].Gain
+	}
+	from, to := e[i-1], e[i]
+	t := float64(frame-from.Frame) / float64(to.Frame-from.Frame)
+	if to.Gain < from.Gain {
+		return to.Gain + (from.Gain-to.Gain)*to.Curve.at(1-t)
+	}
+	return from.Gain + (to.Gain-from.Gain)*to.Curve.at(t)
+}
+
+// Apply multiplies the frames of b by the gain of the envelope. offset is the
+// position of the first frame of b in the envelope, so an envelope can be
+// applied to a stream buffer after buffer.
+func (e Envelope) Apply(b audio.Buffer, offset int) error {
+	if b == nil {
+		return fmt.Errorf("%w: applying an envelope to a nil buffer", audio.ErrInvalidBuffer)
+	}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	nc := 1
+	if f := b.PCMFormat(); f != nil && f.NumChannels > 0 {
+		nc = f.NumChannels
+	}
+	if pb, ok := b.(*audio.PlanarFloatBuffer); ok {
+		nc = len(pb.Data)
+	}
+	return audio.TransformFloat(b, func(samples []float64, frame int) {
+		for i := 0; i+nc <= len(samples); i += nc {
+			gain := e.At(offset + frame + i/nc)
+			for c := i; c < i+nc; c++ {
+				samples[c] *= gain
+			}
+		}
+	})
+}
+
+// FadeIn fades in the first frames of b following the curve c.
+func FadeIn(b audio.Buffer, frames int, c Curve) error {
+	if b == nil {
+		return fmt.Errorf("%w: fading in a nil buffer", audio.ErrInvalidBuffer)
+	}
+	if frames <= 0 {
+		return nil
+	}
+	return Envelope{{Frame: 0, Gain: 0}, {Frame: frames, Gain: 1, Curve: c}}.Apply(b, 0)
+}
+
+// FadeOut fades out the last frames of b following the curve c, the last
+// frame being silent.
+func FadeOut(b audio.Buffer, frames int, c Curve) error {
+	if b == nil {
+		return fmt.Errorf("%w: fading out a nil buffer", audio.ErrInvalidBuffer)
+	}
+	if frames <= 0 {
+		return nil
+	}
+	n := b.NumFrames()
+	return Envelope{{Frame: n - frames - 1, Gain: 1}, {Frame: n - 1, Gain: 0, Curve: c}}.Apply(b, 0)
+}
+
+// String returns the name of the curve.
+func (c Curve) String() string {
+	switch c {
+	case Linear:
+		return "linear"
+	case Exponential:
+		return "exponential"
+	case EqualPower:
+		return "equal power"
+	}
+	return fmt.Sprintf("Curve(%d)", c)
+}
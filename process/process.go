@@ -0,0 +1,63 @@
+/*
+Package process applies common gain based operations to audio buffers: gain
+changes, peak normalization, fades and breakpoint envelopes.
+
+The processors work on any buffer of the audio package, in place. Float
+samples are processed as is, without clipping, while integer samples are
+converted back saturating to their bit depth, see audio.TransformFloat.
+*/
+package process
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/go-audio/audio"
+)
+
+// ErrInvalidEnvelope is returned when the points of an envelope aren't
+// sorted by frame.
+var ErrInvalidEnvelope = errors.New("envelope points aren't sorted by frame")
+
+// Gain multiplies the samples of b by the gain of db decibels.
+func Gain(b audio.Buffer, db float64) error {
+	return Scale(b, audio.DBToGain(db))
+}
+
+// Scale multiplies the samples of b by the linear gain.
+func Scale(b audio.Buffer, gain float64) error {
+	return audio.TransformFloat(b, func(samples []float64, _ int) {
+		for i := range samples {
+			samples[i] *= gain
+		}
+	})
+}
+
+// Peak returns the highest absolute sample value of b, 1 being the full
+// scale. b isn't modified. It returns an error wrapping
+// audio.ErrInvalidBuffer if b is nil.
+func Peak(b audio.Buffer) (float64, error) {
+	if b == nil {
+		return 0, fmt.Errorf("%w: peak of a nil buffer", audio.ErrInvalidBuffer)
+	}
+	var peak float64
+	if fb := b.AsFloatBuffer(); fb != nil {
+		for _, s := range fb.Data {
+			peak = math.Max(peak, math.Abs(s))
+		}
+	}
+	return peak, nil
+}
+
+// Normalize scales b so its peak reaches peakDB decibels relative to the full
+// scale, and returns the applied gain in decibels. Silent buffers are left
+// untouched.
+func Normalize(b audio.Buffer, peakDB float64) (gainDB float64, err error) {
+	peak, err := Peak(b)
+	if err != nil || peak == 0 {
+		return 0, err
+	}
+	gain := audio.DBToGain(peakDB) / peak
+	return audio.GainToDB(gain), Scale(b, gain)
+}
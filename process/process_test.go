@@ -0,0 +1,148 @@
+package process
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestGain(t *testing.T) {
+	buf := &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{0.5, -1, 0}}
+	if err := Gain(buf, -6.0206); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float64{0.25, -0.5, 0} {
+		if math.Abs(buf.Data[i]-want) > 1e-4 {
+			t.Errorf("sample %d: expected %v, got %v", i, want, buf.Data[i])
+		}
+	}
+
+	// int samples saturate
+	ib := &audio.IntBuffer{Format: audio.FormatMono44100, Data: []int{16384, -30000}, SourceBitDepth: 16}
+	if err := Gain(ib, 12); err != nil {
+		t.Fatal(err)
+	}
+	if ib.Data[0] != 32767 || ib.Data[1] != -32768 {
+		t.Errorf("expected saturated samples, got %v", ib.Data)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	buf := &audio.PCMBuffer{Format: audio.FormatMono44100, I16: []int16{-8192, 4096}, DataType: audio.DataTypeI16}
+	gain, err := Normalize(buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !approx(gain, audio.GainToDB(4)) {
+		t.Errorf("expected a gain of %v dB, got %v", audio.GainToDB(4), gain)
+	}
+	if buf.I16[0] != -32768 || buf.I16[1] != 16384 {
+		t.Errorf("expected normalized samples, got %v", buf.I16)
+	}
+
+	silence := &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{0, 0}}
+	if gain, err := Normalize(silence, -1); gain != 0 || err != nil {
+		t.Errorf("expected silence to be left untouched, got %v, %v", gain, err)
+	}
+}
+
+func TestFades(t *testing.T) {
+	ones := func() *audio.FloatBuffer {
+		return &audio.FloatBuffer{Format: audio.FormatStereo44100, Data: []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}}
+	}
+	tests := []struct {
+		name  string
+		curve Curve
+		in    []float64
+		out   []float64
+	}{
+		{"linear", Linear, []float64{0, 0.5, 1, 1, 1}, []float64{1, 1, 1, 0.5, 0}},
+		{"exponential", Exponential, []float64{0, (math.Sqrt(1000) - 1) / 999, 1, 1, 1}, []float64{1, 1, 1, (math.Sqrt(1000) - 1) / 999, 0}},
+		{"equal power", EqualPower, []float64{0, math.Sqrt2 / 2, 1, 1, 1}, []float64{1, 1, 1, math.Sqrt2 / 2, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, out := ones(), ones()
+			if err := FadeIn(in, 2, tt.curve); err != nil {
+				t.Fatal(err)
+			}
+			if err := FadeOut(out, 2, tt.curve); err != nil {
+				t.Fatal(err)
+			}
+			for f := range tt.in {
+				for c := 0; c < 2; c++ {
+					if !approx(in.Data[2*f+c], tt.in[f]) {
+						t.Errorf("fade in frame %d: expected %v, got %v", f, tt.in[f], in.Data[2*f+c])
+					}
+					if !approx(out.Data[2*f+c], tt.out[f]) {
+						t.Errorf("fade out frame %d: expected %v, got %v", f, tt.out[f], out.Data[2*f+c])
+					}
+				}
+			}
+		})
+	}
+
+	if err := FadeIn(nil, 10, Linear); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("fade in: expected ErrInvalidBuffer, got %v", err)
+	}
+	if err := FadeOut(nil, 10, Linear); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("fade out: expected ErrInvalidBuffer, got %v", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	e := Envelope{{Frame: 2, Gain: 1}, {Frame: 6, Gain: 0.5}, {Frame: 6, Gain: 0}, {Frame: 8, Gain: 1}}
+	if err := e.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1, 1, 1, 0.875, 0.75, 0.625, 0, 0.5, 1, 1}
+	for frame, g := range want {
+		if got := e.At(frame); !approx(got, g) {
+			t.Errorf("frame %d: expected %v, got %v", frame, g, got)
+		}
+	}
+
+	// applying the envelope buffer after buffer
+	stream := make([]float64, 10)
+	for start := 0; start < 10; start += 4 {
+		end := min(start+4, 10)
+		buf := &audio.FloatBuffer{Format: audio.FormatMono44100, Data: []float64{1, 1, 1, 1}[:end-start]}
+		if err := e.Apply(buf, start); err != nil {
+			t.Fatal(err)
+		}
+		copy(stream[start:], buf.Data)
+	}
+	for i := range want {
+		if !approx(stream[i], want[i]) {
+			t.Errorf("frame %d: expected %v, got %v", i, want[i], stream[i])
+		}
+	}
+
+	if err := (Envelope{{Frame: 2}, {Frame: 1}}).Apply(&audio.FloatBuffer{}, 0); err != ErrInvalidEnvelope {
+		t.Errorf("expected ErrInvalidEnvelope, got %v", err)
+	}
+	if err := e.Apply(nil, 0); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer, got %v", err)
+	}
+}
+
+func TestPeak_ReadOnly(t *testing.T) {
+	buf := &audio.PCMBuffer{Format: audio.FormatMono44100, U8: []uint8{0, 128, 192}, DataType: audio.DataTypeU8}
+	peak, err := Peak(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak != 1 {
+		t.Errorf("expected a peak of 1, got %v", peak)
+	}
+	if buf.U8[0] != 0 || buf.U8[1] != 128 || buf.U8[2] != 192 {
+		t.Errorf("expected the buffer to be left untouched, got %v", buf.U8)
+	}
+	if _, err := Peak(nil); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer, got %v", err)
+	}
+}
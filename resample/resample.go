@@ -0,0 +1,340 @@
+/*
+Package resample converts audio buffers from one sample rate to another.
+
+The conversion ratio can be any rational number, the input and output rates
+being integers. A Resampler keeps its state between calls so a stream can be
+converted buffer after buffer without discontinuities at the buffer edges.
+*/
+package resample
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-audio/audio"
+)
+
+// maxTableSize is the maximum number of coefficients precomputed for the
+// polyphase filter bank, larger filter banks get interpolated from fewer
+// phases.
+const maxTableSize = 1 << 20
+
+// Quality is the quality/speed tradeoff of the resampling filter.
+type Quality uint8
+
+const (
+	// Linear interpolates linearly between samples. It is the fastest
+	// option but it doesn't filter out aliasing and attenuates high
+	// frequencies.
+	Linear Quality = iota
+	// Sinc interpolates using a polyphase windowed-sinc filter spanning 16
+	// zero crossings on each side. It is a good default for most material.
+	Sinc
+	// BandLimited uses a long windowed-sinc filter spanning 64 zero crossings
+	// on each side, keeping the pass band flat up to 95% of the Nyquist
+	// frequency while strongly rejecting aliases.
+	BandLimited
+)
+
+// filterParams describes the interpolation filter of a quality preset.
+type filterParams struct {
+	// zeroCrossings is the number of zero crossings of the sinc on each side.
+	zeroCrossings int
+	// beta is the Kaiser window parameter.
+	beta float64
+	// rolloff is the cutoff frequency relative to the Nyquist frequency.
+	rolloff float64
+}
+
+var qualityParams = map[Quality]filterParams{
+	Sinc:        {zeroCrossings: 16, beta: 8, rolloff: 0.9},
+	BandLimited: {zeroCrossings: 64, beta: 12, rolloff: 0.95},
+}
+
+// Resampler converts a stream of interleaved samples from one sample rate to
+// another. A Resampler keeps the filter state between calls to Process and
+// must therefore be used for a single stream.
+type Resampler struct {
+	numChannels int
+	fromRate    int
+	toRate      int
+	// up and down are the reduced interpolation and decimation factors.
+	up, down int
+
+	// radius is the number of input samples used on each side of the
+	// interpolated position.
+	radius int
+	kernel func(x float64) float64
+	// table holds the normalized filter coefficients of phases+1 positions
+	// evenly spaced between two input samples. When phases is lower than up,
+	// the coefficients of a position are linearly interpolated between the
+	// ones of the nearest phases.
+	table  [][]float64
+	phases int
+
+	// history holds the input samples of each channel, starting with radius
+	// samples before the current position.
+	history [][]float64
+	// pos is the index in history of the input sample right before the
+	// position of the next output sample, frac/up is the fractional part of
+	// that position.
+	pos, frac int
+	inFrames  int64
+	outFrames int64
+	// format and sourceBitDepth describe the output buffers, based on the
+	// last input buffer.
+	format         *audio.Format
+	sourceBitDepth int
+}
+
+// New returns a resampler converting numChannels channels from the fromRate
+// sample rate to the toRate sample rate. It returns an error wrapping
+// audio.ErrInvalidSampleRate or audio.ErrInvalidNumChannels if a rate or the
+// number of channels isn't strictly positive.
+func New(numChannels, fromRate, toRate int, q Quality) (*Resampler, error) {
+	if fromRate <= 0 || toRate <= 0 {
+		return nil, fmt.Errorf("%w: resampling from %d Hz to %d Hz", audio.ErrInvalidSampleRate, fromRate, toRate)
+	}
+	if numChannels <= 0 {
+		return nil, fmt.Errorf("%w: %d", audio.ErrInvalidNumChannels, numChannels)
+	}
+	g := gcd(fromRate, toRate)
+	r := &Resampler{
+		numChannels: numChannels,
+		fromRate:    fromRate,
+		toRate:      toRate,
+		up:          toRate / g,
+		down:        fromRate / g,
+	}
+
+	params, ok := qualityParams[q]
+	if !ok {
+		r.radius = 1
+		r.kernel = func(x float64) float64 { return math.Max(0, 1-math.Abs(x)) }
+	} else {
+		// when downsampling, the cutoff frequency moves to the new Nyquist
+		// frequency and the filter gets longer.
+		cutoff := params.rolloff * math.Min(1, float64(r.up)/float64(r.down))
+		width := float64(params.zeroCrossings) / cutoff
+		r.radius = int(math.Ceil(width))
+		norm := besselI0(params.beta)
+		r.kernel = func(x float64) float64 {
+			if math.Abs(x) >= width {
+				return 0
+			}
+			w := x / width
+			return cutoff * sinc(cutoff*x) * besselI0(params.beta*math.Sqrt(1-w*w)) / norm
+		}
+	}
+	r.phases = min(r.up, maxTableSize/(2*r.radius))
+	r.table = make([][]float64, r.phases+1)
+	for p := range r.table {
+		r.table[p] = make([]float64, 2*r.radius)
+		r.coefficients(r.table[p], float64(p)/float64(r.phases))
+	}
+	r.Reset()
+	return r, nil
+}
+
+// Reset clears the state of the resampler so it can be used for a new stream.
+func (r *Resampler) Reset() {
+	r.history = make([][]float64, r.numChannels)
+	for i := range r.history {
+		// the stream is preceded by silence
+		r.history[i] = make([]float64, r.radius)
+	}
+	// the first output sample is aligned on the first input sample
+	r.pos = r.radius
+	r.frac = 0
+	r.inFrames = 0
+	r.outFrames = 0
+	r.format = &audio.Format{NumChannels: r.numChannels, SampleRate: r.toRate}
+	r.sourceBitDepth = 0
+}
+
+// Latency returns the number of input frames the resampler needs after a
+// position before being able to output the matching frame. These frames are
+// output by Flush at the end of the stream.
+func (r *Resampler) Latency() int {
+	return r.radius
+}
+
+// Process resamples the frames of buf and returns the output frames
+// available so far as a float buffer at the output sample rate. The samples
+// are normalized as by buf.AsFloatBuffer. Because of the filter latency, the
+// last output frames are only returned once the following input frames are
+// processed, call Flush at the end of the stream to get them.
+//
+// It returns an error wrapping audio.ErrFormatMismatch if buf doesn't have the
+// number of channels and the input sample rate of the resampler.
+func (r *Resampler) Process(buf audio.Buffer) (*audio.FloatBuffer, error) {
+	fb := buf.AsFloatBuffer()
+	if err := audio.CheckFormat(fb, r.numChannels, r.fromRate); err != nil {
+		return nil, err
+	}
+	nc := r.numChannels
+	frames := len(fb.Data) / nc
+	for ch := range r.history {
+		h := r.history[ch]
+		for i := 0; i < frames; i++ {
+			h = append(h, fb.Data[i*nc+ch])
+		}
+		r.history[ch] = h
+	}
+	r.inFrames += int64(frames)
+
+	if fb.Format != nil {
+		format := *fb.Format
+		format.SampleRate = r.toRate
+		r.format = &format
+	}
+	r.sourceBitDepth = fb.SourceBitDepth
+	return &audio.FloatBuffer{
+		Format:         r.format,
+		Data:           r.produce(-1),
+		SourceBitDepth: r.sourceBitDepth,
+	}, nil
+}
+
+// Flush returns the remaining output frames of the stream and resets the
+// resampler. The total number of output frames of a stream of n input
+// frames is ceil(n * toRate / fromRate). The buffer has the format and the
+// source bit depth of the buffers returned by Process.
+func (r *Resampler) Flush() *audio.FloatBuffer {
+	for ch := range r.history {
+		r.history[ch] = append(r.history[ch], make([]float64, r.radius)...)
+	}
+	total := (r.inFrames*int64(r.up) + int64(r.down) - 1) / int64(r.down)
+	out := &audio.FloatBuffer{
+		Format:         r.format,
+		Data:           r.produce(total - r.outFrames),
+		SourceBitDepth: r.sourceBitDepth,
+	}
+	r.Reset()
+	return out
+}
+
+// produce computes up to max output frames (no limit if max is negative)
+// from the samples in history and returns them interleaved.
+func (r *Resampler) produce(max int64) []float64 {
+	var out []float64
+	var coefs []float64
+	if r.phases != r.up {
+		coefs = make([]float64, 2*r.radius)
+	}
+	available := len(r.history[0])
+	for max != 0 && r.pos+r.radius < available {
+		if r.phases == r.up {
+			coefs = r.table[r.frac]
+		} else {
+			r.interpolate(coefs, r.frac)
+		}
+		start := r.pos - r.radius + 1
+		for _, h := range r.history {
+			var v float64
+			for k, c := range coefs {
+				v += c * h[start+k]
+			}
+			out = append(out, v)
+		}
+		r.outFrames++
+		if max > 0 {
+			max--
+		}
+		r.frac += r.down
+		r.pos += r.frac / r.up
+		r.frac %= r.up
+	}
+
+	// drop the samples we won't need anymore
+	if drop := r.pos - r.radius + 1; drop > 0 {
+		if drop > available {
+			drop = available
+		}
+		for ch, h := range r.history {
+			n := copy(h, h[drop:])
+			r.history[ch] = h[:n]
+		}
+		r.pos -= drop
+	}
+	return out
+}
+
+// interpolate computes into coefs the filter coefficients of the position
+// frac/up between two input samples from the ones of the nearest phases of
+// the table.
+func (r *Resampler) interpolate(coefs []float64, frac int) {
+	x := float64(frac) * float64(r.phases) / float64(r.up)
+	p := int(x)
+	t := x - float64(p)
+	lo, hi := r.table[p], r.table[p+1]
+	for k := range coefs {
+		coefs[k] = lo[k] + t*(hi[k]-lo[k])
+	}
+}
+
+// coefficients computes into coefs the normalized filter coefficients of the
+// position offset between two input samples, offset being in [0, 1].
+func (r *Resampler) coefficients(coefs []float64, offset float64) {
+	var sum float64
+	for k := range coefs {
+		coefs[k] = r.kernel(float64(r.radius-1-k) + offset)
+		sum += coefs[k]
+	}
+	if sum != 0 {
+		for k := range coefs {
+			coefs[k] /= sum
+		}
+	}
+}
+
+// Resample converts buf to the toRate sample rate and returns the full
+// converted buffer.
+func Resample(buf audio.Buffer, toRate int, q Quality) (*audio.FloatBuffer, error) {
+	format := buf.PCMFormat()
+	if format == nil {
+		return nil, fmt.Errorf("%w: can't resample a buffer without a sample rate", audio.ErrNilFormat)
+	}
+	nc := format.NumChannels
+	if nc == 0 {
+		nc = 1
+	}
+	r, err := New(nc, format.SampleRate, toRate, q)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.Process(buf)
+	if err != nil {
+		return nil, err
+	}
+	out.Data = append(out.Data, r.Flush().Data...)
+	return out, nil
+}
+
+// sinc returns the normalized sinc function sin(pi*x)/(pi*x).
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// besselI0 returns the zeroth order modified Bessel function of the first
+// kind, used by the Kaiser window.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	x2 := x * x / 4
+	for k := 1; term > sum*1e-16; k++ {
+		term *= x2 / float64(k*k)
+		sum += term
+	}
+	return sum
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,164 @@
+package resample
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func sine(freq float64, rate, frames, channels int) *audio.FloatBuffer {
+	buf := &audio.FloatBuffer{
+		Format: &audio.Format{NumChannels: channels, SampleRate: rate},
+		Data:   make([]float64, frames*channels),
+	}
+	for i := 0; i < frames; i++ {
+		v := 0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+		for ch := 0; ch < channels; ch++ {
+			buf.Data[i*channels+ch] = v
+		}
+	}
+	return buf
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int
+		q        Quality
+		maxErr   float64
+	}{
+		{"linear up", 44100, 48000, Linear, 0.01},
+		{"sinc up", 44100, 48000, Sinc, 0.001},
+		{"band limited up", 44100, 48000, BandLimited, 0.0001},
+		{"sinc down", 48000, 44100, Sinc, 0.001},
+		{"band limited down", 96000, 44100, BandLimited, 0.0001},
+		{"integer ratio", 22050, 44100, Sinc, 0.001},
+		{"odd ratio", 44100, 44101, Sinc, 0.001},
+		{"band limited odd ratio", 44101, 48000, BandLimited, 0.0001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := sine(440, tt.from, tt.from/10, 2)
+			out, err := Resample(in, tt.to, tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Format.SampleRate != tt.to || out.Format.NumChannels != 2 {
+				t.Fatalf("unexpected output format %+v", out.Format)
+			}
+			wantFrames := (in.NumFrames()*tt.to + tt.from - 1) / tt.from
+			if out.NumFrames() != wantFrames {
+				t.Fatalf("expected %d frames, got %d", wantFrames, out.NumFrames())
+			}
+			want := sine(440, tt.to, wantFrames, 2)
+			// ignore the edges where the filter sees the silence around the stream
+			edge := wantFrames / 10
+			var maxErr float64
+			for i := edge * 2; i < len(out.Data)-edge*2; i++ {
+				maxErr = math.Max(maxErr, math.Abs(out.Data[i]-want.Data[i]))
+			}
+			if maxErr > tt.maxErr {
+				t.Errorf("max error of %v, expected less than %v", maxErr, tt.maxErr)
+			}
+		})
+	}
+}
+
+func TestResampler_Streaming(t *testing.T) {
+	in := sine(1000, 44100, 10000, 2)
+	whole, err := Resample(in, 48000, Sinc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(2, 44100, 48000, Sinc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed []float64
+	for start := 0; start < in.NumFrames(); start += 333 {
+		end := start + 333
+		if end > in.NumFrames() {
+			end = in.NumFrames()
+		}
+		chunk := &audio.FloatBuffer{Format: in.Format, Data: in.Data[start*2 : end*2]}
+		out, err := r.Process(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamed = append(streamed, out.Data...)
+	}
+	streamed = append(streamed, r.Flush().Data...)
+	if len(streamed) != len(whole.Data) {
+		t.Fatalf("expected %d samples, got %d", len(whole.Data), len(streamed))
+	}
+	for i := range streamed {
+		if math.Abs(streamed[i]-whole.Data[i]) > 1e-12 {
+			t.Fatalf("sample %d differs: %v vs %v", i, streamed[i], whole.Data[i])
+		}
+	}
+}
+
+func TestResampler_FlushFormat(t *testing.T) {
+	r, err := New(2, 44100, 48000, Linear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &audio.FloatBuffer{
+		Format:         &audio.Format{NumChannels: 2, SampleRate: 44100, Layout: audio.LayoutStereo},
+		Data:           make([]float64, 200),
+		SourceBitDepth: 24,
+	}
+	if _, err := r.Process(in); err != nil {
+		t.Fatal(err)
+	}
+	out := r.Flush()
+	want := audio.Format{NumChannels: 2, SampleRate: 48000, Layout: audio.LayoutStereo}
+	if out.Format == nil || *out.Format != want {
+		t.Errorf("expected the %v format, got %v", &want, out.Format)
+	}
+	if out.SourceBitDepth != 24 {
+		t.Errorf("expected the source bit depth to be kept, got %d", out.SourceBitDepth)
+	}
+	if got := r.Flush().Format; got.SampleRate != 48000 || got.NumChannels != 2 {
+		t.Errorf("unexpected format %v after a reset", got)
+	}
+}
+
+func TestResampler_DC(t *testing.T) {
+	buf := &audio.IntBuffer{
+		Format:         &audio.Format{NumChannels: 1, SampleRate: 8000},
+		Data:           make([]int, 4000),
+		SourceBitDepth: 16,
+	}
+	for i := range buf.Data {
+		buf.Data[i] = 16384
+	}
+	out, err := Resample(buf, 44100, BandLimited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 2000; i < len(out.Data)-2000; i++ {
+		if math.Abs(out.Data[i]-0.5) > 1e-9 {
+			t.Fatalf("expected DC to be kept, got %v at %d", out.Data[i], i)
+		}
+	}
+}
+
+func TestResampler_Errors(t *testing.T) {
+	if _, err := New(1, 0, 44100, Sinc); !errors.Is(err, audio.ErrInvalidSampleRate) {
+		t.Errorf("expected ErrInvalidSampleRate, got %v", err)
+	}
+	r, err := New(1, 44100, 48000, Sinc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Process(sine(440, 48000, 10, 1)); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch for the wrong sample rate, got %v", err)
+	}
+	if _, err := r.Process(sine(440, 44100, 10, 2)); !errors.Is(err, audio.ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch for the wrong number of channels, got %v", err)
+	}
+}
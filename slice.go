@@ -0,0 +1,279 @@
+package audio
+
+import "fmt"
+
+// Slice returns a buffer sharing the storage of buf and containing its frames
+// from start (included) to end (excluded). The capacity of the view is
+// limited so appending to it never overwrites the following frames of buf.
+// Like slicing, Slice panics if the frames are out of range.
+func (buf *TypedBuffer[T]) Slice(start, end int) *TypedBuffer[T] {
+	nc := numChannels(buf.Format)
+	view := *buf
+	view.Data = buf.Data[start*nc : end*nc : end*nc]
+	return &view
+}
+
+// Split returns views (see Slice) of the consecutive chunks of n frames of
+// buf, the last chunk being shorter if needed. It panics if n isn't positive.
+func (buf *TypedBuffer[T]) Split(n int) []*TypedBuffer[T] {
+	var chunks []*TypedBuffer[T]
+	splitFrames(len(buf.Data)/numChannels(buf.Format), n, func(start, end int) {
+		chunks = append(chunks, buf.Slice(start, end))
+	})
+	return chunks
+}
+
+// Append appends the frames of src to buf. Integer samples are rescaled if
+// both buffers have a different SourceBitDepth set. It returns an error
+// wrapping ErrFormatMismatch if the formats aren't compatible, see
+// Format.Compatible.
+func (buf *TypedBuffer[T]) Append(src *TypedBuffer[T]) error {
+	if err := buf.Format.Compatible(src.Format); err != nil {
+		return err
+	}
+	if buf.Format == nil {
+		buf.Format = src.Format.clone()
+	}
+	if len(buf.Data) == 0 && buf.SourceBitDepth == 0 {
+		buf.SourceBitDepth = src.SourceBitDepth
+	}
+	nc := numChannels(src.Format)
+	data := src.Data[:len(src.Data)/nc*nc]
+	from, to := src.SourceBitDepth, buf.SourceBitDepth
+	if _, isFloat := sampleType[T](); isFloat || from == 0 || to == 0 || from == to {
+		buf.Data = append(buf.Data, data...)
+		return nil
+	}
+	offset := len(buf.Data)
+	buf.Data = append(buf.Data, make([]T, len(data))...)
+	ConvertSamples(buf.Data[offset:], data, to, from)
+	return nil
+}
+
+// Slice returns a view of the frames from start to end, see TypedBuffer.Slice.
+func (buf *FloatBuffer) Slice(start, end int) *FloatBuffer {
+	return (*FloatBuffer)(buf.typed().Slice(start, end))
+}
+
+// Split returns views of the chunks of n frames, see TypedBuffer.Split.
+func (buf *FloatBuffer) Split(n int) []*FloatBuffer {
+	var chunks []*FloatBuffer
+	for _, c := range buf.typed().Split(n) {
+		chunks = append(chunks, (*FloatBuffer)(c))
+	}
+	return chunks
+}
+
+// Append appends the frames of src to buf, see TypedBuffer.Append.
+func (buf *FloatBuffer) Append(src *FloatBuffer) error {
+	return buf.typed().Append(src.typed())
+}
+
+// Slice returns a view of the frames from start to end, see TypedBuffer.Slice.
+func (buf *Float32Buffer) Slice(start, end int) *Float32Buffer {
+	return (*Float32Buffer)(buf.typed().Slice(start, end))
+}
+
+// Split returns views of the chunks of n frames, see TypedBuffer.Split.
+func (buf *Float32Buffer) Split(n int) []*Float32Buffer {
+	var chunks []*Float32Buffer
+	for _, c := range buf.typed().Split(n) {
+		chunks = append(chunks, (*Float32Buffer)(c))
+	}
+	return chunks
+}
+
+// Append appends the frames of src to buf, see TypedBuffer.Append.
+func (buf *Float32Buffer) Append(src *Float32Buffer) error {
+	return buf.typed().Append(src.typed())
+}
+
+// Slice returns a view of the frames from start to end, see TypedBuffer.Slice.
+func (buf *IntBuffer) Slice(start, end int) *IntBuffer {
+	return (*IntBuffer)((*TypedBuffer[int])(buf).Slice(start, end))
+}
+
+// Split returns views of the chunks of n frames, see TypedBuffer.Split.
+func (buf *IntBuffer) Split(n int) []*IntBuffer {
+	var chunks []*IntBuffer
+	for _, c := range (*TypedBuffer[int])(buf).Split(n) {
+		chunks = append(chunks, (*IntBuffer)(c))
+	}
+	return chunks
+}
+
+// Append appends the frames of src to buf, see TypedBuffer.Append.
+func (buf *IntBuffer) Append(src *IntBuffer) error {
+	return (*TypedBuffer[int])(buf).Append((*TypedBuffer[int])(src))
+}
+
+// Slice returns a buffer sharing the storage of buf and containing the
+// frames from start to end of each channel, see TypedBuffer.Slice.
+func (buf *PlanarFloatBuffer) Slice(start, end int) *PlanarFloatBuffer {
+	view := *buf
+	view.Data = make([][]float64, len(buf.Data))
+	for i, ch := range buf.Data {
+		view.Data[i] = ch[start:end:end]
+	}
+	return &view
+}
+
+// Split returns views of the chunks of n frames, see TypedBuffer.Split.
+func (buf *PlanarFloatBuffer) Split(n int) []*PlanarFloatBuffer {
+	var chunks []*PlanarFloatBuffer
+	splitFrames(buf.NumFrames(), n, func(start, end int) {
+		chunks = append(chunks, buf.Slice(start, end))
+	})
+	return chunks
+}
+
+// Append appends the frames of src to buf. It returns an error wrapping
+// ErrFormatMismatch if the formats aren't compatible, see Format.Compatible.
+func (buf *PlanarFloatBuffer) Append(src *PlanarFloatBuffer) error {
+	if len(buf.Data) != 0 && len(buf.Data) != len(src.Data) {
+		return fmt.Errorf("%w: appending %d channels to %d channels", ErrFormatMismatch, len(src.Data), len(buf.Data))
+	}
+	if err := buf.Format.Compatible(src.Format); err != nil {
+		return err
+	}
+	if len(buf.Data) == 0 {
+		buf.Data = make([][]float64, len(src.Data))
+	}
+	if buf.Format == nil {
+		buf.Format = src.Format.clone()
+	}
+	if buf.SourceBitDepth == 0 {
+		buf.SourceBitDepth = src.SourceBitDepth
+	}
+	frames := src.NumFrames()
+	for i, ch := range src.Data {
+		buf.Data[i] = append(buf.Data[i], ch[:frames]...)
+	}
+	return nil
+}
+
+// Slice returns a buffer sharing the storage of b and containing the frames
+// of its primary store from start to end, see TypedBuffer.Slice.
+func (b *PCMBuffer) Slice(start, end int) *PCMBuffer {
+	nc := numChannels(b.Format)
+	start, end = start*nc, end*nc
+	view := *b
+	switch b.DataType {
+	case DataTypeI8:
+		view.I8 = b.I8[start:end:end]
+	case DataTypeI16:
+		view.I16 = b.I16[start:end:end]
+	case DataTypeI32:
+		view.I32 = b.I32[start:end:end]
+	case DataTypeF32:
+		view.F32 = b.F32[start:end:end]
+	case DataTypeF64:
+		view.F64 = b.F64[start:end:end]
+	case DataTypeU8:
+		view.U8 = b.U8[start:end:end]
+	case DataTypeI24, DataTypeI24BE:
+		view.I24 = b.I24[3*start : 3*end : 3*end]
+	}
+	return &view
+}
+
+// Split returns views of the chunks of n frames, see TypedBuffer.Split.
+func (b *PCMBuffer) Split(n int) []*PCMBuffer {
+	var chunks []*PCMBuffer
+	splitFrames(b.Len()/numChannels(b.Format), n, func(start, end int) {
+		chunks = append(chunks, b.Slice(start, end))
+	})
+	return chunks
+}
+
+// Append appends the frames of src to the primary store of b, converting
+// them if src uses a different data type. If the DataType of b isn't set, it
+// is set to the data type of src. It returns an error wrapping
+// ErrFormatMismatch if the formats aren't compatible, see Format.Compatible.
+func (b *PCMBuffer) Append(src *PCMBuffer) error {
+	if err := b.Format.Compatible(src.Format); err != nil {
+		return err
+	}
+	if b.Format == nil {
+		b.Format = src.Format.clone()
+	}
+	if b.DataType == DataTypeUnknown {
+		b.DataType = src.DataType
+		b.SourceBitDepth = src.SourceBitDepth
+	}
+	src = src.Slice(0, src.Len()/numChannels(src.Format))
+	offset := b.Len()
+	b.grow(src.Len())
+	copySamples(b, offset, src)
+	return nil
+}
+
+// Concat returns a new buffer holding the frames of all the buffers, in
+// order. The returned buffer has the type of the first buffer, the other
+// buffers being converted as needed. Buffer implementations from other
+// packages are concatenated as a FloatBuffer. Concat returns nil if there are
+// no buffers.
+func Concat(bufs ...Buffer) (Buffer, error) {
+	if len(bufs) == 0 {
+		return nil, nil
+	}
+	var out Buffer
+	var appendBuf func(b Buffer) error
+	switch first := bufs[0].Clone().(type) {
+	case *FloatBuffer:
+		out = first
+		appendBuf = func(b Buffer) error { return first.Append(b.AsFloatBuffer()) }
+	case *Float32Buffer:
+		out = first
+		appendBuf = func(b Buffer) error { return first.Append(b.AsFloat32Buffer()) }
+	case *IntBuffer:
+		out = first
+		appendBuf = func(b Buffer) error { return first.Append(b.AsIntBuffer()) }
+	case *PlanarFloatBuffer:
+		out = first
+		appendBuf = func(b Buffer) error { return first.Append(asPlanarFloatBuffer(b)) }
+	case *PCMBuffer:
+		out = first
+		appendBuf = func(b Buffer) error { return first.Append(asPCMBuffer(b)) }
+	default:
+		fb := bufs[0].AsFloatBuffer().Clone().(*FloatBuffer)
+		out = fb
+		appendBuf = func(b Buffer) error { return fb.Append(b.AsFloatBuffer()) }
+	}
+	for _, b := range bufs[1:] {
+		if err := appendBuf(b); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// asPCMBuffer returns b as a PCMBuffer, converting it to a float64 buffer if
+// it isn't one.
+func asPCMBuffer(b Buffer) *PCMBuffer {
+	if pb, ok := b.(*PCMBuffer); ok {
+		return pb
+	}
+	fb := b.AsFloatBuffer()
+	return &PCMBuffer{Format: fb.Format, F64: fb.Data, DataType: DataTypeF64, SourceBitDepth: uint8(fb.SourceBitDepth / 8)}
+}
+
+// asPlanarFloatBuffer returns b as a PlanarFloatBuffer, converting it if it
+// isn't one.
+func asPlanarFloatBuffer(b Buffer) *PlanarFloatBuffer {
+	if pb, ok := b.(*PlanarFloatBuffer); ok {
+		return pb
+	}
+	return b.AsFloatBuffer().AsPlanarFloatBuffer()
+}
+
+// splitFrames calls fn with the bounds of each consecutive chunk of n frames
+// out of total frames.
+func splitFrames(total, n int, fn func(start, end int)) {
+	if n <= 0 {
+		panic("audio: non-positive chunk size")
+	}
+	for start := 0; start < total; start += n {
+		fn(start, min(start+n, total))
+	}
+}
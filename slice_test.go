@@ -0,0 +1,159 @@
+package audio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	buf := &FloatBuffer{Format: FormatStereo44100, Data: []float64{0, 1, 2, 3, 4, 5, 6, 7}}
+	view := buf.Slice(1, 3)
+	if !reflect.DeepEqual(view.Data, []float64{2, 3, 4, 5}) {
+		t.Fatalf("unexpected view %v", view.Data)
+	}
+	view.Data[0] = -1
+	if buf.Data[2] != -1 {
+		t.Error("expected the view to share the buffer storage")
+	}
+	view.Data = append(view.Data, 42, 42)
+	if buf.Data[6] != 6 {
+		t.Error("appending to the view overwrote the buffer")
+	}
+
+	pcm := &PCMBuffer{Format: FormatStereo44100, I24: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, DataType: DataTypeI24}
+	if got := pcm.Slice(1, 2); !reflect.DeepEqual(got.I24, []byte{7, 8, 9, 10, 11, 12}) || got.NumFrames() != 1 {
+		t.Errorf("unexpected pcm view %+v", got)
+	}
+
+	planar := &PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{0, 1, 2}, {3, 4, 5}}}
+	if got := planar.Slice(1, 3); !reflect.DeepEqual(got.Data, [][]float64{{1, 2}, {4, 5}}) {
+		t.Errorf("unexpected planar view %v", got.Data)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected slicing out of range to panic")
+		}
+	}()
+	buf.Slice(2, 5)
+}
+
+func TestSplit(t *testing.T) {
+	buf := &IntBuffer{Format: FormatMono44100, Data: []int{0, 1, 2, 3, 4}}
+	var got [][]int
+	for _, c := range buf.Split(2) {
+		got = append(got, c.Data)
+	}
+	if want := [][]int{{0, 1}, {2, 3}, {4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	pcm := &PCMBuffer{Format: FormatStereo44100, I16: []int16{0, 1, 2, 3, 4, 5}, DataType: DataTypeI16}
+	if chunks := pcm.Split(2); len(chunks) != 2 || !reflect.DeepEqual(chunks[1].I16, []int16{4, 5}) {
+		t.Errorf("unexpected chunks %+v", chunks)
+	}
+	if chunks := (&Float32Buffer{Format: FormatMono44100}).Split(3); len(chunks) != 0 {
+		t.Errorf("expected no chunks, got %d", len(chunks))
+	}
+}
+
+func TestAppend(t *testing.T) {
+	buf := &IntBuffer{Format: FormatMono44100, Data: []int{256}, SourceBitDepth: 16}
+	if err := buf.Append(&IntBuffer{Format: FormatMono44100, Data: []int{1, -128}, SourceBitDepth: 8}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{256, 256, -32768}; !reflect.DeepEqual(buf.Data, want) {
+		t.Errorf("expected %v got %v", want, buf.Data)
+	}
+
+	err := buf.Append(&IntBuffer{Format: FormatStereo44100, Data: []int{1, 2}})
+	if !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	err = buf.Append(&IntBuffer{Format: FormatMono48000, Data: []int{1}})
+	if !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+
+	// a failed append leaves the buffer untouched
+	planar := &PlanarFloatBuffer{Format: FormatStereo44100}
+	if err := planar.Append(&PlanarFloatBuffer{Format: FormatStereo48000, Data: [][]float64{{1}, {1}}}); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	if planar.Data != nil {
+		t.Errorf("expected no data after a failed append, got %v", planar.Data)
+	}
+
+	pcm := &PCMBuffer{}
+	if err := pcm.Append(&PCMBuffer{Format: FormatMono44100, U8: []uint8{192}, DataType: DataTypeU8}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pcm.Append(&PCMBuffer{Format: FormatMono44100, F64: []float64{-0.5}, DataType: DataTypeF64}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint8{192, 64}; pcm.DataType != DataTypeU8 || !reflect.DeepEqual(pcm.U8, want) {
+		t.Errorf("expected %v got %+v", want, pcm)
+	}
+
+	// 24-bit samples held in int32s keep their scale
+	pcm24 := &PCMBuffer{Format: FormatMono44100, I32: []int32{1 << 22}, DataType: DataTypeI32, SourceBitDepth: 3}
+	if err := pcm24.Append(&PCMBuffer{Format: FormatMono44100, I16: []int16{16384}, DataType: DataTypeI16, SourceBitDepth: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pcm24.Append(&PCMBuffer{Format: FormatMono44100, F64: []float64{-0.5}, DataType: DataTypeF64}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pcm24.Append(&PCMBuffer{Format: FormatMono44100, I32: []int32{1 << 30}, DataType: DataTypeI32}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{1 << 22, 1 << 22, -1 << 22, 1 << 22}; !reflect.DeepEqual(pcm24.I32, want) {
+		t.Errorf("expected %v got %v", want, pcm24.I32)
+	}
+	if want := []float64{0.5, 0.5, -0.5, 0.5}; !reflect.DeepEqual(pcm24.AsF64(), want) {
+		t.Errorf("expected %v got %v", want, pcm24.AsF64())
+	}
+}
+
+func TestConcat(t *testing.T) {
+	a := &FloatBuffer{Format: FormatStereo44100, Data: []float64{0.5, -0.5}}
+	b := &PCMBuffer{Format: FormatStereo44100, I16: []int16{8192, -8192}, DataType: DataTypeI16, SourceBitDepth: 2}
+	c := &PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{1}, {0}}}
+
+	out, err := Concat(a, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fb, ok := out.(*FloatBuffer)
+	if !ok {
+		t.Fatalf("expected a FloatBuffer, got %T", out)
+	}
+	if want := []float64{0.5, -0.5, 0.25, -0.25, 1, 0}; !reflect.DeepEqual(fb.Data, want) {
+		t.Errorf("expected %v got %v", want, fb.Data)
+	}
+	if len(a.Data) != 2 {
+		t.Error("the first buffer was modified")
+	}
+
+	out, err = Concat(c, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]float64{{1, 0.5}, {0, -0.5}}; !reflect.DeepEqual(out.(*PlanarFloatBuffer).Data, want) {
+		t.Errorf("expected %v got %v", want, out.(*PlanarFloatBuffer).Data)
+	}
+
+	out, err = Concat(b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int16{8192, -8192, 16384, -16384}; !reflect.DeepEqual(out.(*PCMBuffer).I16, want) {
+		t.Errorf("expected %v got %v", want, out.(*PCMBuffer).I16)
+	}
+
+	if _, err := Concat(a, &FloatBuffer{Format: FormatMono44100, Data: []float64{1}}); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("expected ErrFormatMismatch, got %v", err)
+	}
+	if out, err := Concat(); out != nil || err != nil {
+		t.Errorf("expected nothing to concatenate, got %v, %v", out, err)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Package spectral analyzes and resynthesizes audio signals in the frequency
+domain.
+
+An STFT splits a signal in overlapping windowed frames and transforms each
+of them, Inverse overlap-adds the frames back into a signal, reconstructing
+it exactly when the frames weren't modified. Spectrogram computes the
+magnitude spectra of the frames of each channel of a buffer, and the
+Magnitude, Phase and Power helpers convert the bins of a spectrum.
+*/
+package spectral
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Magnitude returns the magnitudes of the bins of spec.
+func Magnitude(spec []complex128) []float64 {
+	out := make([]float64, len(spec))
+	for i, v := range spec {
+		out[i] = cmplx.Abs(v)
+	}
+	return out
+}
+
+// Phase returns the phases of the bins of spec, in radians in [-π, π].
+func Phase(spec []complex128) []float64 {
+	out := make([]float64, len(spec))
+	for i, v := range spec {
+		out[i] = cmplx.Phase(v)
+	}
+	return out
+}
+
+// Power returns the squared magnitudes of the bins of spec.
+func Power(spec []complex128) []float64 {
+	out := make([]float64, len(spec))
+	for i, v := range spec {
+		out[i] = real(v)*real(v) + imag(v)*imag(v)
+	}
+	return out
+}
+
+// PowerDB returns the powers of the bins of spec in decibels, -Inf for empty
+// bins.
+func PowerDB(spec []complex128) []float64 {
+	out := Power(spec)
+	for i, p := range out {
+		out[i] = 10 * math.Log10(p)
+	}
+	return out
+}
+
+// FromPolar returns the spectrum whose bins have the magnitudes mag and the
+// phases phase, which must be as long as mag.
+func FromPolar(mag, phase []float64) []complex128 {
+	out := make([]complex128, len(mag))
+	for i, m := range mag {
+		out[i] = cmplx.Rect(m, phase[i])
+	}
+	return out
+}
+
+// BinFrequency returns the frequency in Hz of the bin of a transform of size
+// samples at the sample rate rate.
+func BinFrequency(bin, size int, rate float64) float64 {
+	return float64(bin) * rate / float64(size)
+}
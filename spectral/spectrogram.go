@@ -0,0 +1,52 @@
+package spectral
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// Spectrogram holds the magnitude spectra of the frames of each channel of a
+// buffer.
+type Spectrogram struct {
+	// Format is the format of the analyzed buffer.
+	Format *audio.Format
+	// Size and Hop are the frame size and hop of the STFT.
+	Size, Hop int
+	// Channels holds the magnitudes indexed by channel, frame and bin.
+	Channels [][][]float64
+}
+
+// Spectrogram returns the spectrogram of buf, computed channel by channel.
+// It returns an error wrapping audio.ErrInvalidBuffer if buf or its format
+// is nil.
+func (s *STFT) Spectrogram(buf *audio.FloatBuffer) (*Spectrogram, error) {
+	if buf == nil || buf.Format == nil {
+		return nil, fmt.Errorf("%w: spectrogram of a buffer without format", audio.ErrInvalidBuffer)
+	}
+	sg := &Spectrogram{
+		Format:   buf.Format,
+		Size:     s.size,
+		Hop:      s.hop,
+		Channels: make([][][]float64, buf.Format.NumChannels),
+	}
+	for ch := range sg.Channels {
+		frames := s.Forward(buf.Channel(ch))
+		sg.Channels[ch] = make([][]float64, len(frames))
+		for i, spec := range frames {
+			sg.Channels[ch][i] = Magnitude(spec)
+		}
+	}
+	return sg, nil
+}
+
+// Frequency returns the frequency in Hz of the bin.
+func (sg *Spectrogram) Frequency(bin int) float64 {
+	return BinFrequency(bin, sg.Size, float64(sg.Format.SampleRate))
+}
+
+// Time returns the time of the center of the frame.
+func (sg *Spectrogram) Time(frame int) time.Duration {
+	return sg.Format.Duration(frame * sg.Hop)
+}
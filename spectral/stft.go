@@ -0,0 +1,121 @@
+package spectral
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-audio/audio/fft"
+	"github.com/go-audio/audio/window"
+)
+
+// ErrInvalidHop is returned when the hop of an STFT isn't in [1, size].
+var ErrInvalidHop = errors.New("invalid STFT hop")
+
+// STFT computes short-time Fourier transforms: the spectra of frames of size
+// samples taken every hop samples and multiplied by a window. Frame i is
+// centered on sample i*hop, the signal being padded with zeros, so every
+// sample is covered by the same number of frames. An STFT holds scratch space
+// and so isn't safe for concurrent use.
+type STFT struct {
+	size, hop int
+	window    []float64
+	plan      *fft.RealPlan
+	frame     []float64
+}
+
+// NewSTFT returns an STFT of frames of size samples taken every hop samples,
+// windowed by the periodic variant of w, which defaults to a Hann window if
+// nil. Any window and hop give a perfect reconstruction as long as the
+// windows of the overlapping frames don't all vanish on a sample, which
+// rules out a hop of size with the windows starting at zero, such as Hann.
+// It returns an error wrapping fft.ErrInvalidSize if size isn't positive and
+// ErrInvalidHop if hop isn't in [1, size].
+func NewSTFT(size, hop int, w window.Func) (*STFT, error) {
+	plan, err := fft.NewRealPlan(size)
+	if err != nil {
+		return nil, err
+	}
+	if hop < 1 || hop > size {
+		return nil, fmt.Errorf("%w: %d for frames of %d samples", ErrInvalidHop, hop, size)
+	}
+	if w == nil {
+		w = window.Hann
+	}
+	return &STFT{
+		size:   size,
+		hop:    hop,
+		window: window.Periodic(w, size),
+		plan:   plan,
+		frame:  make([]float64, size),
+	}, nil
+}
+
+// Size returns the number of samples of the frames.
+func (s *STFT) Size() int { return s.size }
+
+// Hop returns the number of samples between the starts of the frames.
+func (s *STFT) Hop() int { return s.hop }
+
+// Bins returns the number of bins of the spectra, Size/2+1.
+func (s *STFT) Bins() int { return s.plan.Bins() }
+
+// Frames returns the number of frames of a signal of n samples.
+func (s *STFT) Frames(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return (n + s.size/2 + s.hop - 1) / s.hop
+}
+
+// start returns the index of the first sample of the frame i.
+func (s *STFT) start(i int) int { return i*s.hop - s.size/2 }
+
+// Forward returns the spectra of the frames of x, see Frames.
+func (s *STFT) Forward(x []float64) [][]complex128 {
+	out := make([][]complex128, s.Frames(len(x)))
+	for i := range out {
+		start := s.start(i)
+		for k := range s.frame {
+			var v float64
+			if j := start + k; j >= 0 && j < len(x) {
+				v = x[j]
+			}
+			s.frame[k] = v * s.window[k]
+		}
+		out[i] = make([]complex128, s.Bins())
+		s.plan.Forward(out[i], s.frame)
+	}
+	return out
+}
+
+// Inverse returns the signal of n samples whose frames have the spectra
+// frames, undoing Forward. The inverse transforms of the frames are windowed
+// again and overlap-added, then divided by the sum of the squared windows
+// covering each sample. It returns an error wrapping fft.ErrInvalidSize if a
+// spectrum doesn't hold Bins bins.
+func (s *STFT) Inverse(frames [][]complex128, n int) ([]float64, error) {
+	out := make([]float64, n)
+	norm := make([]float64, n)
+	for i, spec := range frames {
+		if len(spec) != s.Bins() {
+			return nil, fmt.Errorf("%w: frame %d holds %d bins instead of %d", fft.ErrInvalidSize, i, len(spec), s.Bins())
+		}
+		s.plan.Inverse(s.frame, spec)
+		start := s.start(i)
+		for k, v := range s.frame {
+			if j := start + k; j >= 0 && j < n {
+				w := s.window[k]
+				out[j] += v * w
+				norm[j] += w * w
+			}
+		}
+	}
+	for j, w := range norm {
+		if w > 1e-8 {
+			out[j] /= w
+		} else {
+			out[j] = 0
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,122 @@
+package spectral
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/audio/fft"
+	"github.com/go-audio/audio/window"
+)
+
+func TestSTFT_Inverse(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = rng.Float64()*2 - 1
+	}
+	tests := []struct {
+		name      string
+		size, hop int
+		w         window.Func
+	}{
+		{"hann half overlap", 256, 128, nil},
+		{"hann quarter hop", 256, 64, window.Hann},
+		{"hamming uneven hop", 200, 70, window.Hamming},
+		{"blackman-harris", 512, 100, window.BlackmanHarris},
+		{"rectangular no overlap", 64, 64, window.Rectangular},
+		{"odd size", 101, 33, window.Kaiser(6)},
+	}
+	for _, tt := range tests {
+		s, err := NewSTFT(tt.size, tt.hop, tt.w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames := s.Forward(x)
+		if len(frames) != s.Frames(len(x)) {
+			t.Fatalf("%s: expected %d frames, got %d", tt.name, s.Frames(len(x)), len(frames))
+		}
+		got, err := s.Inverse(frames, len(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range x {
+			if math.Abs(got[i]-x[i]) > 1e-9 {
+				t.Fatalf("%s: sample %d: expected %v, got %v", tt.name, i, x[i], got[i])
+			}
+		}
+	}
+}
+
+func TestNewSTFT(t *testing.T) {
+	if _, err := NewSTFT(0, 1, nil); !errors.Is(err, fft.ErrInvalidSize) {
+		t.Errorf("expected ErrInvalidSize, got %v", err)
+	}
+	for _, hop := range []int{0, 65} {
+		if _, err := NewSTFT(64, hop, nil); !errors.Is(err, ErrInvalidHop) {
+			t.Errorf("hop %d: expected ErrInvalidHop, got %v", hop, err)
+		}
+	}
+	s, _ := NewSTFT(64, 32, nil)
+	if _, err := s.Inverse([][]complex128{make([]complex128, 32)}, 64); !errors.Is(err, fft.ErrInvalidSize) {
+		t.Errorf("expected ErrInvalidSize, got %v", err)
+	}
+}
+
+func TestSTFT_Spectrogram(t *testing.T) {
+	format := &audio.Format{NumChannels: 2, SampleRate: 48000}
+	buf := &audio.FloatBuffer{Format: format, Data: make([]float64, 2*4800)}
+	// a 1.5 kHz sine on the left channel, a 6 kHz sine on the right one
+	for i := 0; i < 4800; i++ {
+		buf.Data[2*i] = math.Sin(2 * math.Pi * 1500 * float64(i) / 48000)
+		buf.Data[2*i+1] = 0.5 * math.Sin(2*math.Pi*6000*float64(i)/48000)
+	}
+	s, _ := NewSTFT(512, 128, window.FlatTop)
+	sg, err := s.Spectrogram(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sg.Time(10), format.Duration(1280); got != want {
+		t.Errorf("expected frame 10 at %v, got %v", want, got)
+	}
+	for ch, want := range []struct{ freq, amplitude float64 }{{1500, 1}, {6000, 0.5}} {
+		mags := sg.Channels[ch][20]
+		peak := 0
+		for bin, m := range mags {
+			if m > mags[peak] {
+				peak = bin
+			}
+		}
+		if got := sg.Frequency(peak); got != want.freq {
+			t.Errorf("channel %d: expected a peak at %v Hz, got %v", ch, want.freq, got)
+		}
+		// the gain of the flat top window is its first coefficient
+		if got := 2 * mags[peak] / (0.21557895 * 512); math.Abs(got-want.amplitude) > 1e-3 {
+			t.Errorf("channel %d: expected an amplitude of %v, got %v", ch, want.amplitude, got)
+		}
+	}
+	if _, err := s.Spectrogram(&audio.FloatBuffer{}); !errors.Is(err, audio.ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer, got %v", err)
+	}
+}
+
+func TestHelpers(t *testing.T) {
+	spec := []complex128{3 + 4i, -2, 0}
+	mag, phase := Magnitude(spec), Phase(spec)
+	if mag[0] != 5 || mag[1] != 2 || phase[1] != math.Pi {
+		t.Errorf("unexpected magnitudes %v and phases %v", mag, phase)
+	}
+	if p := Power(spec); p[0] != 25 || p[2] != 0 {
+		t.Errorf("unexpected powers %v", p)
+	}
+	if db := PowerDB(spec); math.Abs(db[1]-6.0206) > 1e-4 || !math.IsInf(db[2], -1) {
+		t.Errorf("unexpected powers in dB %v", db)
+	}
+	for i, v := range FromPolar(mag, phase) {
+		if math.Abs(real(v)-real(spec[i])) > 1e-12 || math.Abs(imag(v)-imag(spec[i])) > 1e-12 {
+			t.Errorf("bin %d: expected %v, got %v", i, spec[i], v)
+		}
+	}
+}
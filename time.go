@@ -0,0 +1,139 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// Duration returns the duration of the given number of frames at the format's
+// sample rate, rounded to the nearest nanosecond (halves away from zero).
+// It returns 0 if the format or its sample rate isn't set.
+func (f *Format) Duration(frames int) time.Duration {
+	if f == nil || f.SampleRate <= 0 {
+		return 0
+	}
+	rate := uint64(f.SampleRate)
+	n, neg := magnitude(int64(frames))
+	// split the computation to avoid overflowing on long durations
+	sec, rem := n/rate, n%rate
+	if sec > math.MaxInt64/uint64(time.Second) {
+		return time.Duration(saturate(math.MaxUint64, neg))
+	}
+	d := sec*uint64(time.Second) + (rem*uint64(time.Second)+rate/2)/rate
+	return time.Duration(saturate(d, neg))
+}
+
+// FramesFor returns the number of frames lasting d at the format's sample
+// rate, rounded to the nearest frame (halves away from zero). Frame counts
+// round trip through Duration and FramesFor for sample rates up to 1 GHz.
+// It returns 0 if the format or its sample rate isn't set.
+func (f *Format) FramesFor(d time.Duration) int {
+	if f == nil || f.SampleRate <= 0 {
+		return 0
+	}
+	rate := uint64(f.SampleRate)
+	n, neg := magnitude(int64(d))
+	sec, rem := n/uint64(time.Second), n%uint64(time.Second)
+	if sec > math.MaxInt/rate {
+		return int(saturate(math.MaxUint64, neg))
+	}
+	frames := sec*rate + (rem*rate+uint64(time.Second)/2)/uint64(time.Second)
+	return int(saturate(frames, neg))
+}
+
+// magnitude returns the absolute value of x and whether x is negative,
+// without overflowing for math.MinInt64.
+func magnitude(x int64) (uint64, bool) {
+	if x < 0 {
+		return uint64(-(x + 1)) + 1, true
+	}
+	return uint64(x), false
+}
+
+// saturate returns the magnitude n with the given sign, clamped to the int64
+// range.
+func saturate(n uint64, neg bool) int64 {
+	if neg {
+		if n > math.MaxInt64 {
+			return math.MinInt64
+		}
+		return -int64(n)
+	}
+	return int64(min(n, math.MaxInt64))
+}
+
+// timeBounds returns the frames matching the from and to offsets, rounded to
+// the nearest frame and clamped to the numFrames frames of a buffer.
+func timeBounds(f *Format, numFrames int, from, to time.Duration) (start, end int) {
+	start = max(0, min(f.FramesFor(from), numFrames))
+	end = max(start, min(f.FramesFor(to), numFrames))
+	return start, end
+}
+
+// Duration returns the duration of the buffer, see Format.Duration.
+func (buf *TypedBuffer[T]) Duration() time.Duration {
+	return buf.Format.Duration(buf.NumFrames())
+}
+
+// SliceTime returns a view (see Slice) of the frames between the from and to
+// offsets. Offsets are rounded to the nearest frame, the view containing the
+// frames from the rounded from offset up to, but excluding, the rounded to
+// offset. Offsets out of the buffer are clamped to its bounds.
+func (buf *TypedBuffer[T]) SliceTime(from, to time.Duration) *TypedBuffer[T] {
+	return buf.Slice(timeBounds(buf.Format, buf.NumFrames(), from, to))
+}
+
+// Duration returns the duration of the buffer, see Format.Duration.
+func (buf *FloatBuffer) Duration() time.Duration {
+	return buf.typed().Duration()
+}
+
+// SliceTime returns a view of the frames between two offsets, see
+// TypedBuffer.SliceTime.
+func (buf *FloatBuffer) SliceTime(from, to time.Duration) *FloatBuffer {
+	return (*FloatBuffer)(buf.typed().SliceTime(from, to))
+}
+
+// Duration returns the duration of the buffer, see Format.Duration.
+func (buf *Float32Buffer) Duration() time.Duration {
+	return buf.typed().Duration()
+}
+
+// SliceTime returns a view of the frames between two offsets, see
+// TypedBuffer.SliceTime.
+func (buf *Float32Buffer) SliceTime(from, to time.Duration) *Float32Buffer {
+	return (*Float32Buffer)(buf.typed().SliceTime(from, to))
+}
+
+// Duration returns the duration of the buffer, see Format.Duration.
+func (buf *IntBuffer) Duration() time.Duration {
+	return buf.Format.Duration(buf.NumFrames())
+}
+
+// SliceTime returns a view of the frames between two offsets, see
+// TypedBuffer.SliceTime.
+func (buf *IntBuffer) SliceTime(from, to time.Duration) *IntBuffer {
+	return buf.Slice(timeBounds(buf.Format, buf.NumFrames(), from, to))
+}
+
+// Duration returns the duration of the buffer, see Format.Duration.
+func (buf *PlanarFloatBuffer) Duration() time.Duration {
+	return buf.Format.Duration(buf.NumFrames())
+}
+
+// SliceTime returns a view of the frames between two offsets, see
+// TypedBuffer.SliceTime.
+func (buf *PlanarFloatBuffer) SliceTime(from, to time.Duration) *PlanarFloatBuffer {
+	return buf.Slice(timeBounds(buf.Format, buf.NumFrames(), from, to))
+}
+
+// Duration returns the duration of the buffer, see Format.Duration.
+func (b *PCMBuffer) Duration() time.Duration {
+	return b.PCMFormat().Duration(b.NumFrames())
+}
+
+// SliceTime returns a view of the frames between two offsets, see
+// TypedBuffer.SliceTime.
+func (b *PCMBuffer) SliceTime(from, to time.Duration) *PCMBuffer {
+	return b.Slice(timeBounds(b.Format, b.NumFrames(), from, to))
+}
@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormat_Duration(t *testing.T) {
+	tests := []struct {
+		name   string
+		format *Format
+		frames int
+		want   time.Duration
+	}{
+		{"one second", FormatMono44100, 44100, time.Second},
+		{"one frame rounds to nearest", FormatMono44100, 1, 22676 * time.Nanosecond},
+		{"one frame at 48k", FormatStereo48000, 1, 20833 * time.Nanosecond},
+		{"negative", FormatStereo48000, -48000, -time.Second},
+		{"long", FormatMono44100, 44100 * 3600 * 24, 24 * time.Hour},
+		{"saturates", FormatMono44100, math.MaxInt, math.MaxInt64},
+		{"min int", FormatMono44100, math.MinInt, math.MinInt64},
+		{"no sample rate", &Format{NumChannels: 1}, 100, 0},
+		{"nil format", nil, 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Duration(tt.frames); got != tt.want {
+				t.Errorf("Duration(%d) = %v, want %v", tt.frames, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_FramesFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		format *Format
+		d      time.Duration
+		want   int
+	}{
+		{"one second", FormatMono44100, time.Second, 44100},
+		{"rounds down", FormatMono44100, 11 * time.Microsecond, 0},
+		{"rounds up", FormatMono44100, 12 * time.Microsecond, 1},
+		{"halves round away from zero", &Format{SampleRate: 2}, 250 * time.Millisecond, 1},
+		{"negative", FormatMono48000, -time.Second, -48000},
+		{"min duration", FormatMono44100, math.MinInt64, -406750706825296},
+		{"saturates", &Format{SampleRate: 1 << 30}, math.MaxInt64, math.MaxInt},
+		{"nil format", nil, time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.FramesFor(tt.d); got != tt.want {
+				t.Errorf("FramesFor(%v) = %d, want %d", tt.d, got, tt.want)
+			}
+		})
+	}
+
+	for _, f := range []*Format{{SampleRate: 22050}, FormatMono44100, FormatMono96000, {SampleRate: 352800}} {
+		for frames := 0; frames < 100000; frames += 7 {
+			if got := f.FramesFor(f.Duration(frames)); got != frames {
+				t.Fatalf("%d Hz: %d frames round tripped to %d", f.SampleRate, frames, got)
+			}
+		}
+	}
+}
+
+func TestSliceTime(t *testing.T) {
+	format := &Format{NumChannels: 1, SampleRate: 10}
+	buf := &FloatBuffer{Format: format, Data: []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	if buf.Duration() != time.Second {
+		t.Errorf("expected a 1s buffer, got %v", buf.Duration())
+	}
+	tests := []struct {
+		from, to time.Duration
+		want     []float64
+	}{
+		{200 * time.Millisecond, 500 * time.Millisecond, []float64{2, 3, 4}},
+		{240 * time.Millisecond, 460 * time.Millisecond, []float64{2, 3, 4}},
+		{-time.Second, 200 * time.Millisecond, []float64{0, 1}},
+		{800 * time.Millisecond, time.Hour, []float64{8, 9}},
+		{math.MinInt64, math.MaxInt64, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{500 * time.Millisecond, 200 * time.Millisecond, []float64{}},
+	}
+	for _, tt := range tests {
+		if got := buf.SliceTime(tt.from, tt.to).Data; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SliceTime(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+	pcm := &PCMBuffer{Format: format, I16: []int16{0, 1, 2, 3}, DataType: DataTypeI16}
+	if got := pcm.SliceTime(100*time.Millisecond, 300*time.Millisecond); !reflect.DeepEqual(got.I16, []int16{1, 2}) {
+		t.Errorf("unexpected pcm slice %v", got.I16)
+	}
+	if pcm.Duration() != 400*time.Millisecond {
+		t.Errorf("expected a 400ms buffer, got %v", pcm.Duration())
+	}
+}
@@ -0,0 +1,147 @@
+package audio
+
+import "fmt"
+
+// transformChunk is the number of samples converted at once by
+// TransformFloat for the buffers not holding float64 samples.
+const transformChunk = 1024
+
+// TransformFloat calls fn with the interleaved samples of b converted to
+// float64 and writes the samples modified by fn back into b, in place.
+// fn is called with consecutive chunks of whole frames, frame being the
+// index of the first frame of the chunk.
+//
+// FloatBuffer samples are passed as is while other buffers are converted
+// chunk by chunk, integer samples being normalized according to their bit
+// depth and converted back with Float64ToInt, saturating values out of the
+// [-1, 1) range.
+//
+// It returns an error wrapping ErrInvalidBuffer if b is nil or isn't one of
+// the buffers of this package.
+func TransformFloat(b Buffer, fn func(samples []float64, frame int)) error {
+	switch b := b.(type) {
+	case *FloatBuffer:
+		if b != nil {
+			b.typed().transformFloat(fn)
+			return nil
+		}
+	case *Float32Buffer:
+		if b != nil {
+			b.typed().transformFloat(fn)
+			return nil
+		}
+	case *IntBuffer:
+		if b != nil {
+			b.typed().transformFloat(fn)
+			return nil
+		}
+	case *PlanarFloatBuffer:
+		if b != nil {
+			b.transformFloat(fn)
+			return nil
+		}
+	case *PCMBuffer:
+		if b != nil {
+			b.transformFloat(fn)
+			return nil
+		}
+	case interface {
+		transformFloat(fn func([]float64, int))
+	}:
+		// TypedBuffer[T]
+		b.transformFloat(fn)
+		return nil
+	}
+	return fmt.Errorf("%w: can't transform %T", ErrInvalidBuffer, b)
+}
+
+// CheckFormat returns an error wrapping ErrFormatMismatch if the frames of
+// buf can't be processed by a processor set up for numChannels channels at
+// sampleRate Hz, see Format.Compatible. Buffers without a format pass the
+// check.
+func CheckFormat(buf Buffer, numChannels, sampleRate int) error {
+	if buf == nil {
+		return nil
+	}
+	f := &Format{NumChannels: numChannels, SampleRate: sampleRate}
+	return f.Compatible(buf.PCMFormat())
+}
+
+func (buf *TypedBuffer[T]) transformFloat(fn func([]float64, int)) {
+	if buf == nil {
+		return
+	}
+	transformSamples(buf.Data, numChannels(buf.Format), buf.bitDepth(), fn)
+}
+
+func (buf *PlanarFloatBuffer) transformFloat(fn func([]float64, int)) {
+	nc := len(buf.Data)
+	if nc == 0 {
+		return
+	}
+	frames := buf.NumFrames()
+	step := max(1, transformChunk/nc)
+	scratch := make([]float64, step*nc)
+	planes := make([][]float64, nc)
+	for start := 0; start < frames; start += step {
+		end := min(start+step, frames)
+		for i, ch := range buf.Data {
+			planes[i] = ch[start:end]
+		}
+		s := scratch[:(end-start)*nc]
+		Interleave(s, planes)
+		fn(s, start)
+		Deinterleave(planes, s)
+	}
+}
+
+func (b *PCMBuffer) transformFloat(fn func([]float64, int)) {
+	nc := numChannels(b.Format)
+	switch b.DataType {
+	case DataTypeI8:
+		transformSamples(b.I8, nc, b.intBitDepth(), fn)
+	case DataTypeI16:
+		transformSamples(b.I16, nc, b.intBitDepth(), fn)
+	case DataTypeI32:
+		transformSamples(b.I32, nc, b.intBitDepth(), fn)
+	case DataTypeF32:
+		transformSamples(b.F32, nc, 0, fn)
+	case DataTypeF64:
+		transformSamples(b.F64, nc, 0, fn)
+	case DataTypeU8, DataTypeI24, DataTypeI24BE:
+		bitDepth := b.DataType.containerBitDepth()
+		s := convertPCM[int32](b, bitDepth)
+		transformSamples(s, nc, bitDepth, fn)
+		// write the samples back into the existing store so views of
+		// the buffer see the changes
+		for i, v := range s {
+			switch b.DataType {
+			case DataTypeU8:
+				b.U8[i] = uint8(v + 128)
+			case DataTypeI24:
+				PutInt24LE(b.I24[3*i:], v)
+			case DataTypeI24BE:
+				PutInt24BE(b.I24[3*i:], v)
+			}
+		}
+	}
+}
+
+// transformSamples calls fn with the whole frames of data converted to
+// float64, chunk by chunk, and converts the results back into data.
+func transformSamples[T Sample](data []T, numChannels, bitDepth int, fn func([]float64, int)) {
+	data = data[:len(data)/numChannels*numChannels]
+	if f, ok := any(data).([]float64); ok {
+		fn(f, 0)
+		return
+	}
+	step := max(1, transformChunk/numChannels) * numChannels
+	chunk := make([]float64, min(step, len(data)))
+	for start := 0; start < len(data); start += step {
+		end := min(start+step, len(data))
+		c := chunk[:end-start]
+		ConvertSamples(c, data[start:end], 0, bitDepth)
+		fn(c, start/numChannels)
+		ConvertSamples(data[start:end], c, bitDepth, 0)
+	}
+}
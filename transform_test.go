@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTransformFloat(t *testing.T) {
+	double := func(samples []float64, _ int) {
+		for i := range samples {
+			samples[i] *= 2
+		}
+	}
+	tests := []struct {
+		name string
+		buf  Buffer
+		want Buffer
+	}{
+		{"float", &FloatBuffer{Format: FormatStereo44100, Data: []float64{0.25, -2}},
+			&FloatBuffer{Format: FormatStereo44100, Data: []float64{0.5, -4}}},
+		{"float32", &Float32Buffer{Format: FormatStereo44100, Data: []float32{0.25, -2}},
+			&Float32Buffer{Format: FormatStereo44100, Data: []float32{0.5, -4}}},
+		{"int saturates", &IntBuffer{Format: FormatStereo44100, Data: []int{100, 20000, -20000, 0}, SourceBitDepth: 16},
+			&IntBuffer{Format: FormatStereo44100, Data: []int{200, 32767, -32768, 0}, SourceBitDepth: 16}},
+		{"typed", &TypedBuffer[int16]{Format: FormatMono44100, Data: []int16{-3, 4}},
+			&TypedBuffer[int16]{Format: FormatMono44100, Data: []int16{-6, 8}}},
+		{"planar", &PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{0.1, 0.2}, {0.3, 0.4}}},
+			&PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{0.2, 0.4}, {0.6, 0.8}}}},
+		{"pcm i24", &PCMBuffer{Format: FormatMono44100, I24: []byte{1, 0, 0, 0xff, 0xff, 0x3f}, DataType: DataTypeI24},
+			&PCMBuffer{Format: FormatMono44100, I24: []byte{2, 0, 0, 0xfe, 0xff, 0x7f}, DataType: DataTypeI24}},
+		{"pcm u8", &PCMBuffer{Format: FormatMono44100, U8: []uint8{129, 0}, DataType: DataTypeU8},
+			&PCMBuffer{Format: FormatMono44100, U8: []uint8{130, 0}, DataType: DataTypeU8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := TransformFloat(tt.buf, double); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.buf, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, tt.buf)
+			}
+		})
+	}
+}
+
+func TestTransformFloat_Chunks(t *testing.T) {
+	buf := &IntBuffer{Format: FormatStereo44100, Data: make([]int, 2*transformChunk+6)}
+	var frames []int
+	err := TransformFloat(buf, func(samples []float64, frame int) {
+		if len(samples)%2 != 0 {
+			t.Errorf("expected whole frames, got %d samples", len(samples))
+		}
+		frames = append(frames, frame)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, transformChunk / 2, transformChunk}; !reflect.DeepEqual(frames, want) {
+		t.Errorf("expected chunks at frames %v, got %v", want, frames)
+	}
+	var fb *FloatBuffer
+	if err := TransformFloat(fb, func([]float64, int) {}); !errors.Is(err, ErrInvalidBuffer) {
+		t.Errorf("expected ErrInvalidBuffer, got %v", err)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  Buffer
+		err  error
+	}{
+		{"same format", &FloatBuffer{Format: FormatStereo44100}, nil},
+		{"unknown rate", &FloatBuffer{Format: &Format{NumChannels: 2}}, nil},
+		{"no format", &FloatBuffer{}, nil},
+		{"nil", nil, nil},
+		{"channels", &FloatBuffer{Format: FormatMono44100}, ErrFormatMismatch},
+		{"sample rate", &FloatBuffer{Format: FormatStereo48000}, ErrFormatMismatch},
+	}
+	for _, tt := range tests {
+		if err := CheckFormat(tt.buf, 2, 44100); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
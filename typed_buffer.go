@@ -0,0 +1,212 @@
+package audio
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Sample is the set of types audio samples can be stored as. Integer samples
+// are signed and use SourceBitDepth bits, floating point samples are
+// normalized to the [-1, 1] range.
+type Sample interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+var (
+	_ Buffer = (*TypedBuffer[int16])(nil)
+	_ Buffer = (*TypedBuffer[float64])(nil)
+)
+
+// TypedBuffer is an audio buffer with its PCM data formatted as T.
+// FloatBuffer, Float32Buffer and IntBuffer share the layout of
+// TypedBuffer[float64], TypedBuffer[float32] and TypedBuffer[int].
+type TypedBuffer[T Sample] struct {
+	// Format is the representation of the underlying data format
+	Format *Format
+	// Data is the buffer PCM data
+	Data []T
+	// SourceBitDepth helps us know if the source was encoded on
+	// 8, 16, 24, 32, 64 bits. If not set, the bit depth of the format's
+	// sample encoding is used, integer samples defaulting to the size of T.
+	SourceBitDepth int
+}
+
+// PCMFormat returns the buffer format information.
+func (buf *TypedBuffer[T]) PCMFormat() *Format { return buf.Format }
+
+// NumFrames returns the number of frames contained in the buffer.
+func (buf *TypedBuffer[T]) NumFrames() int {
+	if buf == nil || buf.Format == nil {
+		return 0
+	}
+	return len(buf.Data) / numChannels(buf.Format)
+}
+
+// AsFloatBuffer returns a copy of this buffer with its data converted to
+// float64, see ConvertSamples.
+func (buf *TypedBuffer[T]) AsFloatBuffer() *FloatBuffer {
+	return (*FloatBuffer)(ConvertBuffer[float64](buf, 0))
+}
+
+// AsFloat32Buffer returns a copy of this buffer with its data converted to
+// float32, see ConvertSamples.
+func (buf *TypedBuffer[T]) AsFloat32Buffer() *Float32Buffer {
+	return (*Float32Buffer)(ConvertBuffer[float32](buf, 0))
+}
+
+// AsIntBuffer returns a copy of this buffer with its data converted to ints,
+// see ConvertSamples. Integer samples keep their bit depth while float
+// samples are scaled to SourceBitDepth bits (16 if not set).
+func (buf *TypedBuffer[T]) AsIntBuffer() *IntBuffer {
+	return (*IntBuffer)(ConvertBuffer[int](buf, 0))
+}
+
+// Clone creates a clean clone that can be modified without
+// changing the source buffer.
+func (buf *TypedBuffer[T]) Clone() Buffer {
+	if buf == nil {
+		return nil
+	}
+	return buf.clone()
+}
+
+// Channel returns a copy of the samples of the channel i, or nil if the
+// buffer doesn't have such a channel.
+func (buf *TypedBuffer[T]) Channel(i int) []T {
+	return channelSamples(buf.Data, numChannels(buf.Format), i)
+}
+
+func (buf *TypedBuffer[T]) clone() *TypedBuffer[T] {
+	newB := &TypedBuffer[T]{SourceBitDepth: buf.SourceBitDepth}
+	newB.Data = make([]T, len(buf.Data))
+	copy(newB.Data, buf.Data)
+	newB.Format = buf.Format.clone()
+	return newB
+}
+
+// bitDepth returns the bit depth of the samples, 0 for float samples
+// of unknown source.
+func (buf *TypedBuffer[T]) bitDepth() int {
+	if buf.SourceBitDepth != 0 {
+		return buf.SourceBitDepth
+	}
+	if bitDepth := buf.Format.bitDepth(); bitDepth != 0 {
+		return bitDepth
+	}
+	if size, isFloat := sampleType[T](); !isFloat {
+		return size
+	}
+	return 0
+}
+
+// ConvertBuffer returns a copy of buf with its samples converted to D.
+// Integer samples are converted to bitDepth bits or, if bitDepth is 0, to the
+// bit depth of the source capped to the size of D. Float samples converted to
+// an integer type default to DefaultBitDepth bits.
+func ConvertBuffer[D, S Sample](buf *TypedBuffer[S], bitDepth int) *TypedBuffer[D] {
+	from := buf.bitDepth()
+	to := from
+	size, isFloat := sampleType[D]()
+	if isFloat {
+		bitDepth = 0
+	} else {
+		if bitDepth == 0 && to == 0 {
+			bitDepth = DefaultBitDepth
+		}
+		if bitDepth != 0 {
+			to = bitDepth
+		}
+		if to > size {
+			to = size
+		}
+	}
+	newB := &TypedBuffer[D]{SourceBitDepth: to}
+	newB.Data = make([]D, len(buf.Data))
+	ConvertSamples(newB.Data, buf.Data, to, from)
+	newB.Format = buf.Format.clone()
+	return newB
+}
+
+// ConvertSamples converts the samples of src into dst and returns the number
+// of converted samples, the smallest of both lengths. dstBitDepth and
+// srcBitDepth are the bit depths of the integer samples and are ignored for
+// float samples. A bit depth of 0 stands for the size of the integer type,
+// 16 for int16 for instance.
+//
+// Integer samples are shifted between bit depths and float samples are
+// converted as described by IntToFloat64 and Float64ToInt, values that don't
+// fit in the destination being saturated.
+func ConvertSamples[D, S Sample](dst []D, src []S, dstBitDepth, srcBitDepth int) int {
+	n := len(src)
+	if len(dst) < n {
+		n = len(dst)
+	}
+	dst, src = dst[:n], src[:n]
+	dstSize, dstFloat := sampleType[D]()
+	srcSize, srcFloat := sampleType[S]()
+	if dstBitDepth <= 0 {
+		dstBitDepth = dstSize
+	}
+	if srcBitDepth <= 0 {
+		srcBitDepth = srcSize
+	}
+	switch {
+	case srcFloat && dstFloat:
+		for i, s := range src {
+			dst[i] = D(s)
+		}
+	case srcFloat:
+		for i, s := range src {
+			dst[i] = D(Float64ToInt(float64(s), dstBitDepth))
+		}
+	case dstFloat:
+		scale := intScale(srcBitDepth)
+		for i, s := range src {
+			dst[i] = D(float64(s) / scale)
+		}
+	default:
+		for i, s := range src {
+			dst[i] = D(rescaleInt(int64(s), srcBitDepth, dstBitDepth))
+		}
+	}
+	return n
+}
+
+// sampleType returns the size in bits of the sample type T and whether it
+// is a floating point type.
+func sampleType[T Sample]() (size int, isFloat bool) {
+	var zero T
+	switch any(zero).(type) {
+	case int8:
+		return 8, false
+	case int16:
+		return 16, false
+	case int32:
+		return 32, false
+	case int64:
+		return 64, false
+	case int:
+		return strconv.IntSize, false
+	case float32:
+		return 32, true
+	case float64:
+		return 64, true
+	}
+	// types defined from one of the above, such as type Level float32
+	t := reflect.TypeOf(zero)
+	k := t.Kind()
+	return t.Bits(), k == reflect.Float32 || k == reflect.Float64
+}
+
+// channelSamples returns a copy of the samples of the channel i of the
+// interleaved data, or nil if there is no such channel.
+func channelSamples[T any](data []T, numChannels, i int) []T {
+	if i < 0 || i >= numChannels {
+		return nil
+	}
+	out := make([]T, len(data)/numChannels)
+	for f := range out {
+		out[f] = data[f*numChannels+i]
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertSamples(t *testing.T) {
+	t.Run("int16 to float64", func(t *testing.T) {
+		out := make([]float64, 3)
+		ConvertSamples(out, []int16{-32768, 16384, 0}, 0, 16)
+		if want := []float64{-1, 0.5, 0}; !reflect.DeepEqual(out, want) {
+			t.Errorf("expected %v got %v", want, out)
+		}
+	})
+	t.Run("float32 to int8", func(t *testing.T) {
+		out := make([]int8, 4)
+		ConvertSamples(out, []float32{-1, 0.5, 1, 2}, 8, 0)
+		if want := []int8{-128, 64, 127, 127}; !reflect.DeepEqual(out, want) {
+			t.Errorf("expected %v got %v", want, out)
+		}
+	})
+	t.Run("int8 to 24 bits int32", func(t *testing.T) {
+		out := make([]int32, 2)
+		ConvertSamples(out, []int8{-128, 1}, 24, 8)
+		if want := []int32{-8388608, 65536}; !reflect.DeepEqual(out, want) {
+			t.Errorf("expected %v got %v", want, out)
+		}
+	})
+	t.Run("int32 to int16", func(t *testing.T) {
+		out := make([]int16, 2)
+		ConvertSamples(out, []int32{1 << 30, -1 << 31}, 16, 32)
+		if want := []int16{16384, -32768}; !reflect.DeepEqual(out, want) {
+			t.Errorf("expected %v got %v", want, out)
+		}
+	})
+	t.Run("default bit depths", func(t *testing.T) {
+		out := make([]float64, 2)
+		ConvertSamples(out, []int16{16384, -16384}, 0, 0)
+		if want := []float64{0.5, -0.5}; !reflect.DeepEqual(out, want) {
+			t.Errorf("expected %v got %v", want, out)
+		}
+		ints := make([]int32, 2)
+		ConvertSamples(ints, []int16{16384, -32768}, 0, 0)
+		if want := []int32{1 << 30, -1 << 31}; !reflect.DeepEqual(ints, want) {
+			t.Errorf("expected %v got %v", want, ints)
+		}
+		small := make([]int8, 1)
+		ConvertSamples(small, []float64{0.5}, 0, 0)
+		if small[0] != 64 {
+			t.Errorf("expected 64 got %v", small[0])
+		}
+	})
+	t.Run("shortest length", func(t *testing.T) {
+		if n := ConvertSamples(make([]float64, 1), []float32{0.5, 0.25}, 0, 0); n != 1 {
+			t.Errorf("expected 1 converted sample, got %d", n)
+		}
+	})
+}
+
+func TestConvertBuffer(t *testing.T) {
+	src := &TypedBuffer[int16]{Format: FormatStereo44100, Data: []int16{16384, -16384, 8192, 0}}
+	if src.NumFrames() != 2 {
+		t.Errorf("expected 2 frames, got %d", src.NumFrames())
+	}
+
+	f := ConvertBuffer[float32](src, 0)
+	if want := []float32{0.5, -0.5, 0.25, 0}; !reflect.DeepEqual(f.Data, want) {
+		t.Errorf("expected %v got %v", want, f.Data)
+	}
+	if f.SourceBitDepth != 16 {
+		t.Errorf("expected the source bit depth to be kept, got %d", f.SourceBitDepth)
+	}
+
+	i8 := ConvertBuffer[int8](f, 0)
+	if want := []int8{64, -64, 32, 0}; !reflect.DeepEqual(i8.Data, want) {
+		t.Errorf("expected %v got %v", want, i8.Data)
+	}
+	if i8.SourceBitDepth != 8 {
+		t.Errorf("expected the bit depth to be capped to 8 bits, got %d", i8.SourceBitDepth)
+	}
+
+	i32 := ConvertBuffer[int32](src, 24)
+	if want := []int32{4194304, -4194304, 2097152, 0}; !reflect.DeepEqual(i32.Data, want) {
+		t.Errorf("expected %v got %v", want, i32.Data)
+	}
+
+	var buf Buffer = src
+	if got := buf.AsIntBuffer(); !reflect.DeepEqual(got.Data, []int{16384, -16384, 8192, 0}) || got.SourceBitDepth != 16 {
+		t.Errorf("unexpected int buffer %+v", got)
+	}
+	if got := buf.AsFloatBuffer().Data; !reflect.DeepEqual(got, []float64{0.5, -0.5, 0.25, 0}) {
+		t.Errorf("unexpected float data %v", got)
+	}
+	clone := buf.Clone().(*TypedBuffer[int16])
+	clone.Data[0] = 0
+	if src.Data[0] != 16384 {
+		t.Error("modifying the clone changed the source buffer")
+	}
+	if got := src.Channel(1); !reflect.DeepEqual(got, []int16{-16384, 0}) {
+		t.Errorf("unexpected channel %v", got)
+	}
+}
+
+type level float32
+
+func TestSampleType(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		isFloat bool
+		got     func() (int, bool)
+	}{
+		{"int8", 8, false, sampleType[int8]},
+		{"int16", 16, false, sampleType[int16]},
+		{"int32", 32, false, sampleType[int32]},
+		{"int64", 64, false, sampleType[int64]},
+		{"float32", 32, true, sampleType[float32]},
+		{"float64", 64, true, sampleType[float64]},
+		{"defined float32", 32, true, sampleType[level]},
+	}
+	for _, tt := range tests {
+		if size, isFloat := tt.got(); size != tt.size || isFloat != tt.isFloat {
+			t.Errorf("%s: expected %d bits and float %v, got %d bits and float %v", tt.name, tt.size, tt.isFloat, size, isFloat)
+		}
+	}
+}
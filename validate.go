@@ -0,0 +1,127 @@
+package audio
+
+import "fmt"
+
+// Validate returns an error if the format can't describe audio data: a nil
+// format (ErrNilFormat), a number of channels or a sample rate that isn't
+// positive (ErrInvalidNumChannels, ErrInvalidSampleRate), an invalid sample
+// encoding (ErrUnsupportedEncoding) or a layout that doesn't match the
+// number of channels (ErrLayoutMismatch).
+func (f *Format) Validate() error {
+	if f == nil {
+		return ErrNilFormat
+	}
+	if f.NumChannels <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidNumChannels, f.NumChannels)
+	}
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidSampleRate, f.SampleRate)
+	}
+	if f.Sample != (SampleEncoding{}) {
+		if err := f.Sample.Validate(); err != nil {
+			return err
+		}
+	}
+	return f.CheckLayout()
+}
+
+// ValidateBuffer returns an error if the buffer or its format is invalid,
+// see the Validate methods of the buffer implementations. Buffers
+// implemented in other packages only get their format validated.
+func ValidateBuffer(b Buffer) error {
+	if b == nil {
+		return ErrInvalidBuffer
+	}
+	if v, ok := b.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return b.PCMFormat().Validate()
+}
+
+// Validate returns an error if the format is invalid (see Format.Validate),
+// if the data doesn't hold whole frames (ErrChannelMismatch) or if
+// SourceBitDepth can't be the bit depth of the samples (ErrInvalidBitDepth).
+func (buf *TypedBuffer[T]) Validate() error {
+	if buf == nil {
+		return ErrInvalidBuffer
+	}
+	if err := buf.Format.Validate(); err != nil {
+		return err
+	}
+	if nc := buf.Format.NumChannels; len(buf.Data)%nc != 0 {
+		return fmt.Errorf("%w: %d samples for %d channels", ErrChannelMismatch, len(buf.Data), nc)
+	}
+	max := 64
+	if size, isFloat := sampleType[T](); !isFloat {
+		max = size
+	}
+	if buf.SourceBitDepth < 0 || buf.SourceBitDepth > max {
+		return fmt.Errorf("%w: %d bits for %d-bit samples", ErrInvalidBitDepth, buf.SourceBitDepth, max)
+	}
+	return nil
+}
+
+// Validate returns an error if the buffer is invalid, see TypedBuffer.Validate.
+func (buf *FloatBuffer) Validate() error { return buf.typed().Validate() }
+
+// Validate returns an error if the buffer is invalid, see TypedBuffer.Validate.
+func (buf *Float32Buffer) Validate() error { return buf.typed().Validate() }
+
+// Validate returns an error if the buffer is invalid, see TypedBuffer.Validate.
+func (buf *IntBuffer) Validate() error { return (*TypedBuffer[int])(buf).Validate() }
+
+// Validate returns an error if the format is invalid (see Format.Validate) or
+// if the buffer doesn't hold a slice per channel, all of the same length
+// (ErrChannelMismatch).
+func (buf *PlanarFloatBuffer) Validate() error {
+	if buf == nil {
+		return ErrInvalidBuffer
+	}
+	if err := buf.Format.Validate(); err != nil {
+		return err
+	}
+	if len(buf.Data) != buf.Format.NumChannels {
+		return fmt.Errorf("%w: %d planes for %d channels", ErrChannelMismatch, len(buf.Data), buf.Format.NumChannels)
+	}
+	for i, ch := range buf.Data {
+		if len(ch) != len(buf.Data[0]) {
+			return fmt.Errorf("%w: channel %d has %d frames instead of %d", ErrChannelMismatch, i, len(ch), len(buf.Data[0]))
+		}
+	}
+	if buf.SourceBitDepth < 0 || buf.SourceBitDepth > 64 {
+		return fmt.Errorf("%w: %d bits", ErrInvalidBitDepth, buf.SourceBitDepth)
+	}
+	return nil
+}
+
+// Validate returns an error if the format is invalid (see Format.Validate),
+// if DataType isn't a known data type or another store than the one it
+// selects holds samples (ErrDataTypeMismatch), if the primary store doesn't
+// hold whole frames (ErrChannelMismatch) or if SourceBitDepth doesn't fit in
+// the primary store (ErrInvalidBitDepth).
+func (b *PCMBuffer) Validate() error {
+	if b == nil {
+		return ErrInvalidBuffer
+	}
+	if err := b.Format.Validate(); err != nil {
+		return err
+	}
+	container := b.DataType.containerBitDepth()
+	if container == 0 {
+		return fmt.Errorf("%w: unknown data type %d", ErrDataTypeMismatch, b.DataType)
+	}
+	if len(b.I24)%3 != 0 {
+		return fmt.Errorf("%w: %d bytes of packed 24-bit samples", ErrInvalidBuffer, len(b.I24))
+	}
+	stored := len(b.I8) + len(b.I16) + len(b.I32) + len(b.F32) + len(b.F64) + len(b.U8) + len(b.I24)/3
+	if stored != b.Len() {
+		return fmt.Errorf("%w: %d samples outside of the store of data type %d", ErrDataTypeMismatch, stored-b.Len(), b.DataType)
+	}
+	if nc := b.Format.NumChannels; b.Len()%nc != 0 {
+		return fmt.Errorf("%w: %d samples for %d channels", ErrChannelMismatch, b.Len(), nc)
+	}
+	if int(b.SourceBitDepth)*8 > container {
+		return fmt.Errorf("%w: %d bytes for %d-bit samples", ErrInvalidBitDepth, b.SourceBitDepth, container)
+	}
+	return nil
+}
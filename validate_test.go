@@ -0,0 +1,62 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormat_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		format *Format
+		want   error
+	}{
+		{"valid", FormatStereo44100, nil},
+		{"nil", nil, ErrNilFormat},
+		{"no channels", &Format{SampleRate: 44100}, ErrInvalidNumChannels},
+		{"negative sample rate", &Format{NumChannels: 1, SampleRate: -1}, ErrInvalidSampleRate},
+		{"sample encoding", &Format{NumChannels: 1, SampleRate: 8000, Sample: SampleEncoding{Encoding: EncodingALaw, BitDepth: 8}}, nil},
+		{"invalid sample encoding", &Format{NumChannels: 1, SampleRate: 44100, Sample: SampleEncoding{Encoding: EncodingFloat, BitDepth: 24}}, ErrUnsupportedEncoding},
+		{"layout mismatch", &Format{NumChannels: 1, SampleRate: 44100, Layout: LayoutStereo}, ErrLayoutMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.format.Validate(); !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateBuffer(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  Buffer
+		want error
+	}{
+		{"float", &FloatBuffer{Format: FormatStereo44100, Data: []float64{0, 0}}, nil},
+		{"float partial frame", &FloatBuffer{Format: FormatStereo44100, Data: []float64{0, 0, 0}}, ErrChannelMismatch},
+		{"float nil format", &FloatBuffer{Data: []float64{0}}, ErrNilFormat},
+		{"float32 bit depth", &Float32Buffer{Format: FormatMono44100, SourceBitDepth: 65}, ErrInvalidBitDepth},
+		{"int", &IntBuffer{Format: FormatMono44100, Data: []int{1}, SourceBitDepth: 24}, nil},
+		{"typed bit depth", &TypedBuffer[int16]{Format: FormatMono44100, SourceBitDepth: 24}, ErrInvalidBitDepth},
+		{"planar", &PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{0}, {0}}}, nil},
+		{"planar missing channel", &PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{0}}}, ErrChannelMismatch},
+		{"planar uneven channels", &PlanarFloatBuffer{Format: FormatStereo44100, Data: [][]float64{{0}, {0, 1}}}, ErrChannelMismatch},
+		{"pcm", &PCMBuffer{Format: FormatMono44100, I24: []byte{0, 0, 0}, DataType: DataTypeI24, SourceBitDepth: 3}, nil},
+		{"pcm unknown data type", &PCMBuffer{Format: FormatMono44100, I16: []int16{0}}, ErrDataTypeMismatch},
+		{"pcm wrong store", &PCMBuffer{Format: FormatMono44100, I16: []int16{0}, DataType: DataTypeI32}, ErrDataTypeMismatch},
+		{"pcm partial frame", &PCMBuffer{Format: FormatStereo44100, I16: []int16{0}, DataType: DataTypeI16}, ErrChannelMismatch},
+		{"pcm partial sample", &PCMBuffer{Format: FormatMono44100, I24: []byte{0, 0}, DataType: DataTypeI24}, ErrInvalidBuffer},
+		{"pcm bit depth", &PCMBuffer{Format: FormatMono44100, I16: []int16{0}, DataType: DataTypeI16, SourceBitDepth: 3}, ErrInvalidBitDepth},
+		{"pcm invalid format", &PCMBuffer{Format: &Format{NumChannels: 1}, DataType: DataTypeI16}, ErrInvalidSampleRate},
+		{"nil", nil, ErrInvalidBuffer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBuffer(tt.buf); !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
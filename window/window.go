@@ -0,0 +1,126 @@
+/*
+Package window computes the window functions used to design FIR filters and
+to analyze the spectrum of audio signals.
+
+The functions return symmetric windows, suited to filter design, use
+Periodic to get the periodic variant used by spectral analysis.
+*/
+package window
+
+import "math"
+
+// Func returns a window of n samples.
+type Func func(n int) []float64
+
+// Periodic returns the periodic variant of the window f, of n samples: the
+// first n samples of the symmetric window of n+1 samples. Periodic windows
+// overlap-add to a constant, which is required by spectral processing.
+func Periodic(f Func, n int) []float64 {
+	return f(n + 1)[:n]
+}
+
+// Apply multiplies the samples of x by the window w, which must be at least
+// as long as x.
+func Apply(x, w []float64) {
+	for i := range x {
+		x[i] *= w[i]
+	}
+}
+
+// Rectangular returns a window of n ones.
+func Rectangular(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+// Hann returns a Hann (raised cosine) window of n samples.
+func Hann(n int) []float64 {
+	return cosineSum(n, 0.5, 0.5)
+}
+
+// Hamming returns a Hamming window of n samples.
+func Hamming(n int) []float64 {
+	return cosineSum(n, 0.54, 0.46)
+}
+
+// Blackman returns a Blackman window of n samples.
+func Blackman(n int) []float64 {
+	return cosineSum(n, 0.42, 0.5, 0.08)
+}
+
+// BlackmanHarris returns a 4-term Blackman-Harris window of n samples, whose
+// side lobes are 92 dB below the main lobe.
+func BlackmanHarris(n int) []float64 {
+	return cosineSum(n, 0.35875, 0.48829, 0.14128, 0.01168)
+}
+
+// FlatTop returns a flat top window of n samples. Its nearly flat main lobe
+// measures the amplitude of sinusoids accurately whatever their position
+// between the bins of a spectrum.
+func FlatTop(n int) []float64 {
+	return cosineSum(n, 0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368)
+}
+
+// Kaiser returns a function computing Kaiser windows with the shape
+// parameter beta, higher values trading a wider main lobe for lower side
+// lobes (beta 8.6 is close to a Blackman window).
+func Kaiser(beta float64) Func {
+	return func(n int) []float64 {
+		w := make([]float64, n)
+		if n == 1 {
+			w[0] = 1
+			return w
+		}
+		norm := besselI0(beta)
+		for i := range w {
+			r := 2*float64(i)/float64(n-1) - 1
+			w[i] = besselI0(beta*math.Sqrt(1-r*r)) / norm
+		}
+		return w
+	}
+}
+
+// KaiserBeta returns the Kaiser beta parameter giving a stop band attenuation
+// of attenuation decibels.
+func KaiserBeta(attenuation float64) float64 {
+	switch {
+	case attenuation > 50:
+		return 0.1102 * (attenuation - 8.7)
+	case attenuation >= 21:
+		return 0.5842*math.Pow(attenuation-21, 0.4) + 0.07886*(attenuation-21)
+	}
+	return 0
+}
+
+// cosineSum returns the generalized cosine window of n samples with the
+// coefficients a, the signs of the terms alternating.
+func cosineSum(n int, a ...float64) []float64 {
+	w := make([]float64, n)
+	if n == 1 {
+		w[0] = 1
+		return w
+	}
+	for i := range w {
+		x := 2 * math.Pi * float64(i) / float64(n-1)
+		sign := 1.0
+		for k, ak := range a {
+			w[i] += sign * ak * math.Cos(float64(k)*x)
+			sign = -sign
+		}
+	}
+	return w
+}
+
+// besselI0 returns the modified Bessel function of the first kind of order
+// zero, computed from its power series.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > sum*1e-16; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+	}
+	return sum
+}
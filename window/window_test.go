@@ -0,0 +1,61 @@
+package window
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWindows(t *testing.T) {
+	tests := []struct {
+		name  string
+		f     Func
+		edge  float64
+		coeff float64 // coherent gain, the mean of a long window
+	}{
+		{"rectangular", Rectangular, 1, 1},
+		{"hann", Hann, 0, 0.5},
+		{"hamming", Hamming, 0.08, 0.54},
+		{"blackman", Blackman, 0, 0.42},
+		{"blackman-harris", BlackmanHarris, 6e-5, 0.35875},
+		{"flat top", FlatTop, -4.21051e-4, 0.21557895},
+		{"kaiser", Kaiser(0), 1, 1},
+	}
+	for _, tt := range tests {
+		w := tt.f(1001)
+		if math.Abs(w[0]-tt.edge) > 1e-9 || math.Abs(w[1000]-tt.edge) > 1e-9 {
+			t.Errorf("%s: expected edges of %v, got %v and %v", tt.name, tt.edge, w[0], w[1000])
+		}
+		if math.Abs(w[500]-1) > 1e-8 {
+			t.Errorf("%s: expected a center of 1, got %v", tt.name, w[500])
+		}
+		var sum float64
+		for _, v := range w {
+			sum += v
+		}
+		if mean := sum / 1001; math.Abs(mean-tt.coeff) > 1e-3 {
+			t.Errorf("%s: expected a coherent gain of %v, got %v", tt.name, tt.coeff, mean)
+		}
+		if w := tt.f(1); w[0] != 1 {
+			t.Errorf("%s: expected a single sample window of 1, got %v", tt.name, w)
+		}
+	}
+}
+
+func TestPeriodic(t *testing.T) {
+	// periodic Hann windows hopped by half their length sum to 1
+	w := Periodic(Hann, 8)
+	for i := 0; i < 4; i++ {
+		if sum := w[i] + w[i+4]; math.Abs(sum-1) > 1e-12 {
+			t.Errorf("sample %d: expected overlapping windows to sum to 1, got %v", i, sum)
+		}
+	}
+}
+
+func TestKaiserBeta(t *testing.T) {
+	if got := KaiserBeta(60); math.Abs(got-5.65326) > 1e-5 {
+		t.Errorf("expected 5.65326, got %v", got)
+	}
+	if got := KaiserBeta(10); got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+}